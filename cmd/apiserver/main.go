@@ -43,10 +43,11 @@ func main() {
 
 	redisCash := rdb.New(cfg)
 
-	natsPublisher, err := nats.NewPublisher()
+	natsPublisher, err := nats.NewPublisher(cfg)
 	if err != nil {
-		zap.L().With(zap.Error(err)).Panic("main/nats.NewPublisher()")
+		zap.L().With(zap.Error(err)).Panic("main/nats.NewPublisher(cfg)")
 	}
+	defer natsPublisher.Close()
 
 	serviceLayer := service.New(pgStore, redisCash, natsPublisher)
 
@@ -55,6 +56,12 @@ func main() {
 		zap.L().With(zap.Error(err)).Panic("error creating standard project")
 	}
 
+	go serviceLayer.RunRankRebalancer(ctx, cfg.RankRebalanceInterval, cfg.RankMaxLength)
+
+	go serviceLayer.RunPurger(ctx, cfg.PurgeInterval, cfg.PurgeAfter, cfg.OutboxRetention, cfg.PurgeBatchSize)
+
+	go serviceLayer.RunOutboxRelay(ctx, cfg.OutboxRelayInterval, cfg.OutboxBatchSize, cfg.OutboxRetryDelay, cfg.OutboxMaxRetryDelay)
+
 	server := apiserver.New(
 		apiserver.Config{BindAddress: cfg.BindAddress},
 		serviceLayer)
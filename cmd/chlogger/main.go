@@ -48,15 +48,41 @@ func main() {
 
 	zap.L().Debug(natsBindAddr.String())
 
-	sub, err := nats.NewGoodsEventSubscriber(ch, cfg.CHBatchSize, natsBindAddr.String())
+	sub, err := nats.NewGoodsEventSubscriber(
+		ch,
+		cfg.CHBatchSize,
+		cfg.CHFlushInterval,
+		cfg.CHShutdownFlushTimeout,
+		natsBindAddr.String(),
+	)
 	if err != nil {
 		zap.L().With(zap.Error(err)).Panic("nats.NewGoodsEventSubscriber(ctx, ch)")
 	}
 
-	_, err = sub.SubscribeEventLogger("goods_logs")
-	if err != nil {
-		zap.L().With(zap.Error(err)).Panic("sub.SubscribeEventLogger(\"goods_logs\")")
+	if cfg.NatsJetStream {
+		err = sub.ConsumeEventLogger(ctx, cfg.NatsStream, cfg.NatsDurable, "goods_logs")
+		if err != nil {
+			zap.L().With(zap.Error(err)).Panic("sub.ConsumeEventLogger(...)")
+		}
+	} else {
+		_, err = sub.SubscribeEventLogger("goods_logs")
+		if err != nil {
+			zap.L().With(zap.Error(err)).Panic("sub.SubscribeEventLogger(\"goods_logs\")")
+		}
+
+		sub.RunFlusher(ctx)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err = sub.Shutdown(shutdownCtx); err != nil {
+		zap.L().With(zap.Error(err)).Error("main/sub.Shutdown(shutdownCtx)")
+	}
+
+	if err = ch.Close(); err != nil {
+		zap.L().With(zap.Error(err)).Warn("main/ch.Close()")
 	}
 
-	<-ctx.Done()
+	zap.L().Info("chlogger stopped")
 }
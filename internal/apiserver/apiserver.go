@@ -68,11 +68,28 @@ func (s *APIServer) Run(ctx context.Context) error {
 func (s *APIServer) configRouter() {
 	s.router.Route("/api", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
-			r.Post("/good/create", s.createGood)
-			r.Patch("/good/update", s.updateGoods)
-			r.Delete("/good/remove", s.removeGoods)
+			r.Post("/project/create", s.createProject)
+			r.Patch("/project/update", s.updateProject)
+			r.Delete("/project/remove", s.removeProject)
+			r.Get("/project/list", s.getProjects)
+			r.Get("/project/get", s.getProject)
+
+			r.Post("/good/create", s.idempotent(s.createGood))
+			r.Post("/good/bulk-create", s.bulkCreateGoods)
+			r.Post("/good/bulk-remove", s.bulkRemoveGoods)
+			r.Post("/good/bulk-update", s.bulkUpdateGoods)
+			r.Patch("/good/update", s.idempotent(s.updateGoods))
+			r.Delete("/good/remove", s.idempotent(s.removeGoods))
+			r.Patch("/good/restore", s.restoreGoods)
 			r.Get("/good/list", s.getGoods)
-			r.Patch("/good/reprioritize", s.reprioritizeGood)
+			r.Get("/good/get", s.getGood)
+			r.Get("/good/trash", s.getTrash)
+			r.Patch("/good/reprioritize", s.idempotent(s.reprioritizeGood))
+			r.Patch("/good/reorder", s.reorderGoods)
+
+			r.Post("/batch", s.executeBatch)
+
+			r.Get("/health", s.getHealth)
 		})
 	})
 }
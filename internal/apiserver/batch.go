@@ -0,0 +1,66 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+type BatchResponse struct {
+	Results *[]model.BatchResult `json:"results"`
+}
+
+func (s *APIServer) executeBatch(w http.ResponseWriter, r *http.Request) {
+	var request model.BatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	results, err := s.service.ExecuteBatch(r.Context(), request)
+
+	var (
+		batchErr   *model.BatchError
+		violations model.ValidationErrors
+	)
+
+	switch {
+	case errors.Is(err, model.ErrEmptyBatch):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.batch.empty", make(map[string]any))
+
+		return
+	case err != nil && !errors.As(err, &batchErr):
+		// the commit failed after every operation succeeded
+		writeFailedResponse(w, err, "executeBatch/s.service.ExecuteBatch(r.Context(), request)", make(map[string]any))
+
+		return
+	case err == nil:
+		writeOkResponse(w, http.StatusOK, BatchResponse{Results: results})
+
+		return
+	}
+
+	details := map[string]any{
+		"index": batchErr.Index,
+	}
+
+	switch {
+	case errors.As(err, &violations):
+		details["fields"] = violations
+		writeErrorResponse(w, http.StatusBadRequest, 8, "errors.validation", details)
+	case errors.Is(err, model.ErrUnknownBatchOp):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.batch.unknownOp", details)
+	case errors.Is(err, model.ErrWrongBatchRef):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.batch.wrongRef", details)
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", details)
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", details)
+	default:
+		writeFailedResponse(w, err, "executeBatch/s.service.ExecuteBatch(r.Context(), request)", details)
+	}
+}
@@ -0,0 +1,58 @@
+package apiserver
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+func etag(version int) string {
+	return strconv.Quote(strconv.Itoa(version))
+}
+
+// parseIfMatch returns the version expected by the If-Match header, zero when
+// any version is fine. A header that is not a version ETag never matches.
+func parseIfMatch(r *http.Request) int {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0
+	}
+
+	unquoted, err := strconv.Unquote(header)
+	if err != nil {
+		return -1
+	}
+
+	version, err := strconv.Atoi(unquoted)
+	if err != nil || version < 1 {
+		return -1
+	}
+
+	return version
+}
+
+func writeGoodResponse(w http.ResponseWriter, statusCode int, goods *model.Goods) {
+	w.Header().Set("ETag", etag(goods.Version))
+	writeOkResponse(w, statusCode, goods)
+}
+
+// writePreconditionFailed answers a failed If-Match with the current good.
+func (s *APIServer) writePreconditionFailed(w http.ResponseWriter, r *http.Request, id, projectID int64) {
+	current, err := s.service.GetGood(r.Context(), model.Goods{ID: id, ProjectID: projectID})
+
+	switch {
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "writePreconditionFailed/s.service.GetGood(...)", make(map[string]any))
+
+		return
+	}
+
+	writeGoodResponse(w, http.StatusPreconditionFailed, current)
+}
@@ -11,11 +11,30 @@ import (
 )
 
 type service interface {
+	CreateProject(ctx context.Context, project model.Project) (*model.Project, error)
+	GetProject(ctx context.Context, project model.Project) (*model.Project, error)
+	GetProjects(ctx context.Context, params model.ProjectListParams) (*[]model.Project, error)
+	UpdateProject(ctx context.Context, request model.UpdateProjectRequest) (*model.Project, error)
+	DeleteProject(ctx context.Context, project model.Project) (*model.Project, error)
+
 	CreateGoods(ctx context.Context, goods model.Goods) (*model.Goods, error)
+	BulkCreateGoods(ctx context.Context, request model.BulkCreateRequest) (*[]model.BulkItemResult, error)
+	BulkRemoveGoods(ctx context.Context, request model.BulkRemoveRequest) (*[]model.Goods, error)
+	BulkUpdateGoods(ctx context.Context, request model.BulkUpdateRequest) (*[]model.Goods, error)
 	UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error)
 	DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, error)
+	RestoreGoods(ctx context.Context, goods model.Goods) (*model.Goods, error)
+	GetGood(ctx context.Context, goods model.Goods) (*model.Goods, error)
 	GetGoods(ctx context.Context, params model.ListParams) (*model.GetListResponse, error)
+	GetTrash(ctx context.Context, params model.ListParams) (*model.GetListResponse, error)
 	ReprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, error)
+	ReorderGoods(ctx context.Context, request model.ReorderRequest) (*[]model.Goods, error)
+
+	ExecuteBatch(ctx context.Context, request model.BatchRequest) (*[]model.BatchResult, error)
+
+	BrokerStatus() string
+
+	idempotencyStore
 }
 
 type ErrorResponse struct {
@@ -29,6 +48,20 @@ type GetListResponse struct {
 	Goods *[]model.Goods    `json:"goods"`
 }
 
+type ProjectListResponse struct {
+	Projects *[]model.Project `json:"projects"`
+}
+
+type BulkResponse struct {
+	Results *[]model.BulkItemResult `json:"results"`
+}
+
+type BulkChangeResponse struct {
+	DryRun   bool           `json:"dryRun"`
+	Affected int            `json:"affected"`
+	Goods    *[]model.Goods `json:"goods"`
+}
+
 type ReprioritizeResponse struct {
 	Priorities *[]model.Goods `json:"priorities"`
 }
@@ -49,14 +82,140 @@ func (s *APIServer) createGood(w http.ResponseWriter, r *http.Request) {
 	}
 
 	goods, err := s.service.CreateGoods(r.Context(), requestGoods)
-	if err != nil {
-		zap.L().With(zap.Error(err)).Warn("createGood/s.service.CreateGoods(r.Context(), requestGoods)")
-		writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", make(map[string]any))
+
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "createGood/s.service.CreateGoods(r.Context(), requestGoods)", make(map[string]any))
+
+		return
+	}
+
+	writeGoodResponse(w, http.StatusCreated, goods)
+}
+
+func (s *APIServer) bulkCreateGoods(w http.ResponseWriter, r *http.Request) {
+	var request model.BulkCreateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	if err := model.DecodeQueryParams(*r.URL, &request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	results, err := s.service.BulkCreateGoods(r.Context(), request)
+
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "bulkCreateGoods/s.service.BulkCreateGoods(r.Context(), request)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, BulkResponse{Results: results})
+}
+
+func (s *APIServer) bulkRemoveGoods(w http.ResponseWriter, r *http.Request) {
+	var request model.BulkRemoveRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	if err := model.DecodeQueryParams(*r.URL, &request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	removedGoods, err := s.service.BulkRemoveGoods(r.Context(), request)
+
+	switch {
+	case errors.Is(err, model.ErrWrongBulkFilter):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.good.wrongBulkFilter", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "bulkRemoveGoods/s.service.BulkRemoveGoods(r.Context(), request)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, BulkChangeResponse{
+		DryRun:   request.DryRun,
+		Affected: len(*removedGoods),
+		Goods:    removedGoods,
+	})
+}
+
+func (s *APIServer) bulkUpdateGoods(w http.ResponseWriter, r *http.Request) {
+	var request model.BulkUpdateRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	if err := model.DecodeQueryParams(*r.URL, &request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	updatedGoods, err := s.service.BulkUpdateGoods(r.Context(), request)
+
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case errors.Is(err, model.ErrWrongBulkFilter):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.good.wrongBulkFilter", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrEmptyBulkUpdate):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.good.emptyBulkUpdate", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "bulkUpdateGoods/s.service.BulkUpdateGoods(r.Context(), request)", make(map[string]any))
 
 		return
 	}
 
-	writeOkResponse(w, http.StatusCreated, goods)
+	writeOkResponse(w, http.StatusOK, BulkChangeResponse{
+		DryRun:   request.DryRun,
+		Affected: len(*updatedGoods),
+		Goods:    updatedGoods,
+	})
 }
 
 func (s *APIServer) updateGoods(w http.ResponseWriter, r *http.Request) {
@@ -75,25 +234,34 @@ func (s *APIServer) updateGoods(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	updateRequest.Version = parseIfMatch(r)
+
 	goods, err := s.service.UpdateGoods(r.Context(), updateRequest)
 
 	switch {
+	case errors.Is(err, model.ErrVersionMismatch):
+		s.writePreconditionFailed(w, r, updateRequest.ID, updateRequest.ProjectID)
+
+		return
 	case errors.Is(err, model.ErrGoodNotFound):
 		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
 
 		return
-	case errors.Is(err, model.ErrBlankName):
-		writeErrorResponse(w, http.StatusBadRequest, 1, "errors.good.blankName", make(map[string]any))
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
 
 		return
 	case err != nil:
-		zap.L().With(zap.Error(err)).Warn("updateGoods/s.service.UpdateGoods(r.Context(), updateRequest)")
-		writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", make(map[string]any))
+		writeFailedResponse(w, err, "updateGoods/s.service.UpdateGoods(r.Context(), updateRequest)", make(map[string]any))
 
 		return
 	}
 
-	writeOkResponse(w, http.StatusOK, goods)
+	writeGoodResponse(w, http.StatusOK, goods)
 }
 
 func (s *APIServer) removeGoods(w http.ResponseWriter, r *http.Request) {
@@ -108,21 +276,80 @@ func (s *APIServer) removeGoods(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	goods.Version = parseIfMatch(r)
+
 	deletedGoods, err := s.service.DeleteGoods(r.Context(), goods)
 
+	switch {
+	case errors.Is(err, model.ErrVersionMismatch):
+		s.writePreconditionFailed(w, r, goods.ID, goods.ProjectID)
+
+		return
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "removeGoods/s.service.DeleteGoods(r.Context(), goods)", make(map[string]any))
+
+		return
+	}
+
+	writeGoodResponse(w, http.StatusOK, deletedGoods)
+}
+
+func (s *APIServer) restoreGoods(w http.ResponseWriter, r *http.Request) {
+	var goods model.Goods
+
+	if err := model.DecodeQueryParams(*r.URL, &goods); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	restoredGoods, err := s.service.RestoreGoods(r.Context(), goods)
+
+	switch {
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "restoreGoods/s.service.RestoreGoods(r.Context(), goods)", make(map[string]any))
+
+		return
+	}
+
+	writeGoodResponse(w, http.StatusOK, restoredGoods)
+}
+
+func (s *APIServer) getGood(w http.ResponseWriter, r *http.Request) {
+	var goods model.Goods
+
+	if err := model.DecodeQueryParams(*r.URL, &goods); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	result, err := s.service.GetGood(r.Context(), goods)
+
 	switch {
 	case errors.Is(err, model.ErrGoodNotFound):
 		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
 
 		return
 	case err != nil:
-		zap.L().With(zap.Error(err)).Warn("removeGoods/s.service.DeleteGoods(r.Context(), goods)")
-		writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", make(map[string]any))
+		writeFailedResponse(w, err, "getGood/s.service.GetGood(r.Context(), goods)", make(map[string]any))
 
 		return
 	}
 
-	writeOkResponse(w, http.StatusOK, deletedGoods)
+	writeGoodResponse(w, http.StatusOK, result)
 }
 
 func (s *APIServer) getGoods(w http.ResponseWriter, r *http.Request) {
@@ -134,10 +361,46 @@ func (s *APIServer) getGoods(w http.ResponseWriter, r *http.Request) {
 	}
 
 	result, err := s.service.GetGoods(r.Context(), params)
-	if err != nil {
-		zap.L().With(zap.Error(err)).Warn("getGoods/s.service.GetGoods(r.Context(), *params)")
 
-		writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", make(map[string]any))
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "getGoods/s.service.GetGoods(r.Context(), *params)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, result)
+}
+
+func (s *APIServer) getTrash(w http.ResponseWriter, r *http.Request) {
+	var params model.ListParams
+	if err := model.DecodeQueryParams(*r.URL, &params); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	result, err := s.service.GetTrash(r.Context(), params)
+
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "getTrash/s.service.GetTrash(r.Context(), params)", make(map[string]any))
 
 		return
 	}
@@ -164,10 +427,73 @@ func (s *APIServer) reprioritizeGood(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	goods.Version = parseIfMatch(r)
+
 	changedGoods, err := s.service.ReprioritizeGoods(r.Context(), goods)
+
+	switch {
+	case errors.Is(err, model.ErrVersionMismatch):
+		s.writePreconditionFailed(w, r, goods.ID, goods.ProjectID)
+
+		return
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "reprioritizeGood/s.service.ReprioritizeGoods(r.Context(), goods)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, ReprioritizeResponse{Priorities: changedGoods})
+}
+
+func (s *APIServer) reorderGoods(w http.ResponseWriter, r *http.Request) {
+	var request model.ReorderRequest
+
+	err := json.NewDecoder(r.Body).Decode(&request)
 	if err != nil {
-		zap.L().With(zap.Error(err)).Warn("reprioritizeGood/s.service.ReprioritizeGoods(r.Context(), goods)")
-		writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", make(map[string]any))
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	if err = model.DecodeQueryParams(*r.URL, &request); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	changedGoods, err := s.service.ReorderGoods(r.Context(), request)
+
+	switch {
+	case errors.Is(err, model.ErrGoodNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 3, "errors.good.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrWrongReorder):
+		writeErrorResponse(w, http.StatusBadRequest, 2, "errors.good.wrongReorder", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "reorderGoods/s.service.ReorderGoods(r.Context(), request)", make(map[string]any))
 
 		return
 	}
@@ -186,6 +512,35 @@ func writeOkResponse(w http.ResponseWriter, statusCode int, data any) {
 	}
 }
 
+// writeValidationErrorResponse lists the violated fields with their codes in
+// the details of the response.
+func writeValidationErrorResponse(w http.ResponseWriter, err error) {
+	details := make(map[string]any)
+
+	var violations model.ValidationErrors
+	if errors.As(err, &violations) {
+		for field, code := range violations {
+			details[field] = code
+		}
+	}
+
+	writeErrorResponse(w, http.StatusBadRequest, 8, "errors.validation", details)
+}
+
+// writeFailedResponse writes the response for errors any call to the service
+// can return: a conflicting concurrent change or an unexpected failure, which
+// is logged with the call that returned it.
+func writeFailedResponse(w http.ResponseWriter, err error, call string, details map[string]any) {
+	if errors.Is(err, model.ErrConflict) {
+		writeErrorResponse(w, http.StatusConflict, 9, "errors.conflict", details)
+
+		return
+	}
+
+	zap.L().With(zap.Error(err)).Warn(call)
+	writeErrorResponse(w, http.StatusInternalServerError, 5, "errors.InternalServerError", details)
+}
+
 func writeErrorResponse(w http.ResponseWriter, statusCode int, errorCode int, message string, details map[string]any) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
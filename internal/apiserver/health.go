@@ -0,0 +1,14 @@
+package apiserver
+
+import "net/http"
+
+type HealthResponse struct {
+	Broker string `json:"broker"`
+}
+
+// getHealth reports the state of the connection to the message broker. The
+// server keeps serving requests while the broker is unreachable, so it is
+// reported without failing the check.
+func (s *APIServer) getHealth(w http.ResponseWriter, _ *http.Request) {
+	writeOkResponse(w, http.StatusOK, HealthResponse{Broker: s.service.BrokerStatus()})
+}
@@ -0,0 +1,153 @@
+package apiserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// idempotencyStoreTimeout bounds saving the outcome of a request, which
+	// outlives the request context.
+	idempotencyStoreTimeout = 5 * time.Second
+)
+
+// replayedHeaders are the response headers kept with an idempotency record.
+var replayedHeaders = []string{"Content-Type", "ETag"}
+
+type idempotencyStore interface {
+	ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*model.IdempotencyRecord, error)
+	StoreIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord) error
+	ReleaseIdempotencyKey(ctx context.Context, key string) error
+}
+
+// responseRecorder passes the response through and keeps a copy of it.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+
+	return r.ResponseWriter.Write(data) //nolint: wrapcheck
+}
+
+// requestHash identifies a request by everything that affects its outcome.
+func requestHash(r *http.Request, body []byte) string {
+	hash := sha256.New()
+
+	for _, part := range []string{r.Method, r.URL.Path, r.URL.RawQuery, r.Header.Get("If-Match")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// idempotent executes a request with an Idempotency-Key once and replays the
+// stored response for repeats. Responses with a server error or a conflict
+// are not stored, so the request can be retried with the same key. The
+// outcome is saved even if the client is gone, the change may have been made
+// already.
+func (s *APIServer) idempotent(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := requestHash(r, body)
+
+		record, err := s.service.ReserveIdempotencyKey(r.Context(), key, hash)
+
+		switch {
+		case errors.Is(err, model.ErrIdempotencyKeyReused):
+			writeErrorResponse(w, http.StatusUnprocessableEntity, 7, "errors.idempotency.keyReused", make(map[string]any))
+
+			return
+		case errors.Is(err, model.ErrIdempotencyInProgress):
+			writeErrorResponse(w, http.StatusConflict, 7, "errors.idempotency.inProgress", make(map[string]any))
+
+			return
+		case err != nil:
+			// without redis the request is served as if it had no key
+			zap.L().With(zap.Error(err)).Warn("idempotent/s.service.ReserveIdempotencyKey(...)")
+			next(w, r)
+
+			return
+		case record != nil:
+			for name, value := range record.Header {
+				w.Header().Set(name, value)
+			}
+
+			w.WriteHeader(record.StatusCode)
+
+			if _, err = w.Write(record.Body); err != nil {
+				zap.L().With(zap.Error(err)).Warn("idempotent/w.Write(record.Body)")
+			}
+
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next(recorder, r)
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyStoreTimeout)
+		defer cancel()
+
+		if recorder.statusCode == http.StatusConflict || recorder.statusCode >= http.StatusInternalServerError {
+			if err = s.service.ReleaseIdempotencyKey(ctx, key); err != nil {
+				zap.L().With(zap.Error(err)).Warn("idempotent/s.service.ReleaseIdempotencyKey(ctx, key)")
+			}
+
+			return
+		}
+
+		header := make(map[string]string, len(replayedHeaders))
+
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				header[name] = value
+			}
+		}
+
+		err = s.service.StoreIdempotencyRecord(ctx, key, model.IdempotencyRecord{
+			RequestHash: hash,
+			StatusCode:  recorder.statusCode,
+			Header:      header,
+			Body:        recorder.body.Bytes(),
+		})
+		if err != nil {
+			zap.L().With(zap.Error(err)).Warn("idempotent/s.service.StoreIdempotencyRecord(...)")
+		}
+	}
+}
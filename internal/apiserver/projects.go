@@ -0,0 +1,144 @@
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+func (s *APIServer) createProject(w http.ResponseWriter, r *http.Request) {
+	var requestProject model.Project
+
+	if err := json.NewDecoder(r.Body).Decode(&requestProject); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	project, err := s.service.CreateProject(r.Context(), requestProject)
+
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "createProject/s.service.CreateProject(r.Context(), requestProject)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusCreated, project)
+}
+
+func (s *APIServer) updateProject(w http.ResponseWriter, r *http.Request) {
+	var updateRequest model.UpdateProjectRequest
+
+	err := json.NewDecoder(r.Body).Decode(&updateRequest)
+	if err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadBody", make(map[string]any))
+
+		return
+	}
+
+	if err = model.DecodeQueryParams(*r.URL, &updateRequest); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	project, err := s.service.UpdateProject(r.Context(), updateRequest)
+
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "updateProject/s.service.UpdateProject(r.Context(), updateRequest)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, project)
+}
+
+func (s *APIServer) removeProject(w http.ResponseWriter, r *http.Request) {
+	var project model.Project
+
+	if err := model.DecodeQueryParams(*r.URL, &project); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	deletedProject, err := s.service.DeleteProject(r.Context(), project)
+
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "removeProject/s.service.DeleteProject(r.Context(), project)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, deletedProject)
+}
+
+func (s *APIServer) getProjects(w http.ResponseWriter, r *http.Request) {
+	var params model.ProjectListParams
+	if err := model.DecodeQueryParams(*r.URL, &params); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	projects, err := s.service.GetProjects(r.Context(), params)
+
+	switch {
+	case errors.Is(err, model.ErrValidation):
+		writeValidationErrorResponse(w, err)
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "getProjects/s.service.GetProjects(r.Context(), params)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, ProjectListResponse{Projects: projects})
+}
+
+func (s *APIServer) getProject(w http.ResponseWriter, r *http.Request) {
+	var project model.Project
+
+	if err := model.DecodeQueryParams(*r.URL, &project); err != nil {
+		writeErrorResponse(w, http.StatusBadRequest, 0, "error.FailedToReadQuery", make(map[string]any))
+
+		return
+	}
+
+	result, err := s.service.GetProject(r.Context(), project)
+
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound):
+		writeErrorResponse(w, http.StatusNotFound, 4, "errors.project.notFound", make(map[string]any))
+
+		return
+	case err != nil:
+		writeFailedResponse(w, err, "getProject/s.service.GetProject(r.Context(), project)", make(map[string]any))
+
+		return
+	}
+
+	writeOkResponse(w, http.StatusOK, result)
+}
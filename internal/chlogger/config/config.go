@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
@@ -13,8 +15,22 @@ type Config struct {
 	CHPassword  string `env:"CH_PASSWORD" env-default:""`
 	CHBatchSize int    `env:"CH_BATCH_SIZE" env-default:"3"`
 
+	// CHFlushInterval is how long events wait at most for a batch to fill up.
+	// On shutdown the subscriptions are drained within ShutdownTimeout, then
+	// the queued events are saved within CHShutdownFlushTimeout.
+	CHFlushInterval        time.Duration `env:"CH_FLUSH_INTERVAL" env-default:"5s"`
+	CHShutdownFlushTimeout time.Duration `env:"CH_SHUTDOWN_FLUSH_TIMEOUT" env-default:"5s"`
+	ShutdownTimeout        time.Duration `env:"SHUTDOWN_TIMEOUT" env-default:"10s"`
+
 	NatsHost string `env:"NATS_BINDADDR" env-default:"localhost"`
 	NatsPort string `env:"NATS_HOST" env-default:"4222"`
+
+	// NatsJetStream reads events from the NatsStream stream through the
+	// NatsDurable consumer and acknowledges them once saved, instead of
+	// subscribing to core NATS.
+	NatsJetStream bool   `env:"NATS_JETSTREAM" env-default:"false"`
+	NatsStream    string `env:"NATS_STREAM" env-default:"GOODS_LOGS"`
+	NatsDurable   string `env:"NATS_DURABLE" env-default:"chlogger"`
 }
 
 func New() *Config {
@@ -0,0 +1,117 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.uber.org/zap"
+)
+
+// redeliveryDelay is how long events that failed to save wait to be delivered
+// again.
+const redeliveryDelay = 5 * time.Second
+
+// ConsumeEventLogger saves the events of subject read from the stream through
+// a durable pull consumer, queueSize at a time or as many as arrived within
+// the flush interval. Events are acknowledged only after they are saved, so
+// events published while chlogger is down or ClickHouse fails are saved later.
+// It blocks until ctx is done, unacknowledged events are delivered again on
+// the next start.
+func (s *Subscriber) ConsumeEventLogger(ctx context.Context, stream, durable, subject string) error {
+	js, err := jetstream.New(s.conn)
+	if err != nil {
+		return fmt.Errorf("jetstream.New(s.conn): %w", err)
+	}
+
+	// the apiserver sets the rest of the stream config on its first publish
+	_, err = js.CreateStream(ctx, jetstream.StreamConfig{
+		Name:     stream,
+		Subjects: []string{subject},
+		Storage:  jetstream.FileStorage,
+	})
+	if err != nil && !errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+		return fmt.Errorf("js.CreateStream(...): %w", err)
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, stream, jetstream.ConsumerConfig{
+		Durable:       durable,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		FilterSubject: subject,
+	})
+	if err != nil {
+		return fmt.Errorf("js.CreateOrUpdateConsumer(...): %w", err)
+	}
+
+	for ctx.Err() == nil {
+		if err = s.consumeBatch(ctx, consumer); err != nil {
+			zap.L().With(zap.Error(err)).Warn("ConsumeEventLogger/s.consumeBatch(ctx, consumer)")
+
+			select {
+			case <-ctx.Done():
+			case <-time.After(redeliveryDelay):
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *Subscriber) consumeBatch(ctx context.Context, consumer jetstream.Consumer) error {
+	batch, err := consumer.Fetch(s.queueSize, jetstream.FetchMaxWait(s.flushInterval))
+	if err != nil {
+		return fmt.Errorf("consumer.Fetch(...): %w", err)
+	}
+
+	messages := make([]jetstream.Msg, 0, s.queueSize)
+	events := make([]model.GoodsEvent, 0, s.queueSize)
+
+	for msg := range batch.Messages() {
+		var event model.GoodsEvent
+		if err = json.Unmarshal(msg.Data(), &event); err != nil {
+			zap.L().With(zap.Error(err)).Warn("consumeBatch/json.Unmarshal(msg.Data(), &event)", zap.String("msg", string(msg.Data())))
+
+			// redelivery would fail the same way
+			if err = msg.Term(); err != nil {
+				zap.L().With(zap.Error(err)).Warn("consumeBatch/msg.Term()")
+			}
+
+			continue
+		}
+
+		messages = append(messages, msg)
+		events = append(events, event)
+	}
+
+	if err = batch.Error(); err != nil {
+		zap.L().With(zap.Error(err)).Warn("consumeBatch/batch.Error()")
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err = s.store.SaveGoodsEvents(ctx, &events); err != nil {
+		for _, msg := range messages {
+			if err := msg.NakWithDelay(redeliveryDelay); err != nil {
+				zap.L().With(zap.Error(err)).Warn("consumeBatch/msg.NakWithDelay(redeliveryDelay)")
+			}
+		}
+
+		return fmt.Errorf("s.store.SaveGoodsEvents(ctx, &events): %w", err)
+	}
+
+	for _, msg := range messages {
+		if err = msg.Ack(); err != nil {
+			zap.L().With(zap.Error(err)).Warn("consumeBatch/msg.Ack()")
+		}
+	}
+
+	zap.L().Debug("saved events from stream", zap.Int("events", len(events)))
+
+	return nil
+}
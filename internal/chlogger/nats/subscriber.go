@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/Saaghh/hezzl-hr/internal/model"
 	"github.com/nats-io/nats.go"
@@ -17,25 +18,44 @@ type Store interface {
 }
 
 type Subscriber struct {
-	conn      *nats.Conn
-	queue     []model.GoodsEvent
-	store     Store
-	queueSize int
-	mu        *sync.Mutex
+	conn                 *nats.Conn
+	closed               chan struct{}
+	queue                []model.GoodsEvent
+	store                Store
+	queueSize            int
+	flushInterval        time.Duration
+	shutdownFlushTimeout time.Duration
+	mu                   *sync.Mutex
 }
 
-func NewGoodsEventSubscriber(store Store, queueSize int, bindAddr string) (*Subscriber, error) {
-	conn, err := nats.Connect(bindAddr)
+// NewGoodsEventSubscriber saves events in batches of queueSize, a smaller
+// batch waits for flushInterval at most. On shutdown the queue is saved within
+// shutdownFlushTimeout.
+func NewGoodsEventSubscriber(
+	store Store,
+	queueSize int,
+	flushInterval time.Duration,
+	shutdownFlushTimeout time.Duration,
+	bindAddr string,
+) (*Subscriber, error) {
+	closed := make(chan struct{})
+
+	conn, err := nats.Connect(bindAddr, nats.ClosedHandler(func(_ *nats.Conn) {
+		close(closed)
+	}))
 	if err != nil {
 		return nil, fmt.Errorf("nats.Connect(nats.DefaultURL): %w", err)
 	}
 
 	return &Subscriber{
-		queue:     make([]model.GoodsEvent, 0, queueSize),
-		conn:      conn,
-		store:     store,
-		queueSize: queueSize,
-		mu:        new(sync.Mutex),
+		queue:                make([]model.GoodsEvent, 0, queueSize),
+		conn:                 conn,
+		closed:               closed,
+		store:                store,
+		queueSize:            queueSize,
+		flushInterval:        flushInterval,
+		shutdownFlushTimeout: shutdownFlushTimeout,
+		mu:                   new(sync.Mutex),
 	}, nil
 }
 
@@ -54,6 +74,8 @@ func (s *Subscriber) processEvent(m *nats.Msg) {
 	var goods model.GoodsEvent
 	if err := json.Unmarshal(m.Data, &goods); err != nil {
 		zap.L().With(zap.Error(err)).Warn("processEvent/json.Unmarshal(m.Data, &goods)", zap.String("msg", string(m.Data)))
+
+		return
 	}
 
 	s.mu.Lock()
@@ -61,7 +83,7 @@ func (s *Subscriber) processEvent(m *nats.Msg) {
 	s.queue = append(s.queue, goods)
 
 	if len(s.queue) >= s.queueSize {
-		if err := s.flushQueue(); err != nil {
+		if err := s.flushQueue(context.Background()); err != nil {
 			zap.L().With(zap.Error(err)).Warn("processEvent/s.flushQueue()", zap.String("events", strconv.Itoa(len(s.queue))))
 		}
 	}
@@ -69,9 +91,64 @@ func (s *Subscriber) processEvent(m *nats.Msg) {
 	s.mu.Unlock()
 }
 
-func (s *Subscriber) flushQueue() error {
-	if err := s.store.SaveGoodsEvents(context.Background(), &s.queue); err != nil {
-		return fmt.Errorf("s.store.SaveGoodsEvents(s.ctx, &s.queue): %w", err)
+// RunFlusher saves the queued events every flush interval, so events are not
+// held back while too few arrive to fill a batch. It blocks until ctx is done.
+func (s *Subscriber) RunFlusher(ctx context.Context) {
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+
+			if err := s.flushQueue(ctx); err != nil {
+				zap.L().With(zap.Error(err)).Warn("RunFlusher/s.flushQueue(ctx)", zap.String("events", strconv.Itoa(len(s.queue))))
+			}
+
+			s.mu.Unlock()
+		}
+	}
+}
+
+// Shutdown drains the subscriptions, so events already received are queued,
+// saves the queue and closes the connection. The drain waits for the deadline
+// of ctx at most, the queue is saved even after it within the shutdown flush timeout.
+func (s *Subscriber) Shutdown(ctx context.Context) error {
+	if err := s.conn.Drain(); err != nil {
+		zap.L().With(zap.Error(err)).Warn("Shutdown/s.conn.Drain()")
+		s.conn.Close()
+	}
+
+	select {
+	case <-s.closed:
+	case <-ctx.Done():
+		s.conn.Close()
+	}
+
+	// the drain may have used up the deadline, the queue gets its own
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.shutdownFlushTimeout)
+	defer cancel()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.flushQueue(flushCtx); err != nil {
+		return fmt.Errorf("s.flushQueue(flushCtx): %w", err)
+	}
+
+	return nil
+}
+
+func (s *Subscriber) flushQueue(ctx context.Context) error {
+	if len(s.queue) == 0 {
+		return nil
+	}
+
+	if err := s.store.SaveGoodsEvents(ctx, &s.queue); err != nil {
+		return fmt.Errorf("s.store.SaveGoodsEvents(ctx, &s.queue): %w", err)
 	}
 
 	s.queue = s.queue[:0]
@@ -45,25 +45,24 @@ func New(ctx context.Context, cfg Config) (*Clickhouse, error) {
 
 	zap.L().Debug(store.dbURI)
 
-	if err := store.connect(); err != nil {
-		return nil, fmt.Errorf("store.connect(): %w", err)
+	if err := store.connect(ctx); err != nil {
+		return nil, fmt.Errorf("store.connect(ctx): %w", err)
 	}
 
-	go func() {
-		defer func() {
-			err := store.conn.Close()
-			if err != nil {
-				zap.L().With(zap.Error(err)).Warn("store.conn.Close()")
-			}
-		}()
+	return &store, nil
+}
 
-		<-ctx.Done()
-	}()
+// Close closes the connection. Events are saved until it is called, so the
+// queue can be flushed on shutdown.
+func (c *Clickhouse) Close() error {
+	if err := c.conn.Close(); err != nil {
+		return fmt.Errorf("c.conn.Close(): %w", err)
+	}
 
-	return &store, nil
+	return nil
 }
 
-func (c *Clickhouse) connect() error {
+func (c *Clickhouse) connect(ctx context.Context) error {
 	conn := clickhouse.OpenDB(&clickhouse.Options{
 		Addr: []string{c.cfg.BindAddr},
 		Auth: clickhouse.Auth{
@@ -74,8 +73,8 @@ func (c *Clickhouse) connect() error {
 		// Debug: true,
 	})
 
-	if err := conn.Ping(); err != nil {
-		return fmt.Errorf("conn.Ping(): %w", err)
+	if err := conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("conn.PingContext(ctx): %w", err)
 	}
 
 	c.conn = conn
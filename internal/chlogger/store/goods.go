@@ -16,7 +16,11 @@ func (c *Clickhouse) SaveGoodsEvents(ctx context.Context, goods *[]model.GoodsEv
 		return fmt.Errorf("c.conn.Begin(): %w", err)
 	}
 
-	stmt, err := tx.PrepareContext(ctx, "INSERT INTO goods_logs (id, project_id, name, description, priority, removed, event_time) VALUES (?, ?, ?, ?, ?, ?, ?)")
+	stmt, err := tx.PrepareContext(ctx, `
+	INSERT INTO goods_logs (
+		event_id, schema_version, id, project_id, name, description, priority, removed, version, event_type, event_time,
+		previous_name, previous_description, previous_priority, previous_removed
+	) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		return fmt.Errorf("tx.PrepareContext(...): %w", err)
 	}
@@ -29,19 +33,38 @@ func (c *Clickhouse) SaveGoodsEvents(ctx context.Context, goods *[]model.GoodsEv
 	}()
 
 	for _, event := range *goods {
-		removed := 0
-		if event.Removed {
-			removed = 1
+		current := event.Goods()
+
+		var (
+			previousName, previousDescription *string
+			previousPriority                  *int
+			previousRemoved                   *bool
+		)
+
+		// previous values stay NULL for created goods
+		if previous := event.Previous; previous != nil {
+			previousName = &previous.Name
+			previousDescription = &previous.Description
+			previousPriority = &previous.Priority
+			previousRemoved = &previous.Removed
 		}
 
 		if _, err = stmt.ExecContext(ctx,
-			event.ID,
-			event.ProjectID,
-			event.Name,
-			event.Description,
-			event.Priority,
-			removed,
+			event.EventID,
+			event.SchemaVersion,
+			current.ID,
+			current.ProjectID,
+			current.Name,
+			current.Description,
+			current.Priority,
+			current.Removed,
+			current.Version,
+			event.Type,
 			event.EventTime,
+			previousName,
+			previousDescription,
+			previousPriority,
+			previousRemoved,
 		); err != nil {
 			if err := tx.Rollback(); err != nil {
 				zap.L().Error("tx.Rollback()", zap.Error(err))
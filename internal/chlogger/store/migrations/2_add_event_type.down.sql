@@ -0,0 +1 @@
+ALTER TABLE goods_logs DROP COLUMN event_type;
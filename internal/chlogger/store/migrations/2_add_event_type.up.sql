@@ -0,0 +1 @@
+ALTER TABLE goods_logs ADD COLUMN event_type String DEFAULT '' AFTER removed;
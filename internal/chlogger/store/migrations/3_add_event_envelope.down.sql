@@ -0,0 +1,8 @@
+ALTER TABLE goods_logs
+    DROP COLUMN previous_removed,
+    DROP COLUMN previous_priority,
+    DROP COLUMN previous_description,
+    DROP COLUMN previous_name,
+    DROP COLUMN version,
+    DROP COLUMN schema_version,
+    DROP COLUMN event_id;
@@ -0,0 +1,8 @@
+ALTER TABLE goods_logs
+    ADD COLUMN event_id UUID FIRST,
+    ADD COLUMN schema_version UInt8 DEFAULT 1 AFTER event_id,
+    ADD COLUMN version UInt32 DEFAULT 0 AFTER removed,
+    ADD COLUMN previous_name Nullable(String) AFTER event_time,
+    ADD COLUMN previous_description Nullable(String) AFTER previous_name,
+    ADD COLUMN previous_priority Nullable(Int32) AFTER previous_description,
+    ADD COLUMN previous_removed Nullable(UInt8) AFTER previous_priority;
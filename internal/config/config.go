@@ -1,11 +1,17 @@
 package config
 
 import (
+	"errors"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+const (
+	RankingInteger  = "integer"
+	RankingLexorank = "lexorank"
+)
+
 type Config struct {
 	BindAddress string `env:"BIND_ADDR" env-default:":8080"`
 	LogLevel    string `env:"LOG_LEVEL" env-default:"debug"`
@@ -20,6 +26,58 @@ type Config struct {
 	RedisPassword       string        `env:"REDIS_DB" env-default:""`
 	RedisDB             int           `env:"REDIS_DB"`
 	RedisDefaultTimeout time.Duration `env:"REDIS_TIMEOUT"`
+
+	// NatsURLs is a comma separated list of servers. The first one set of
+	// NatsCredsFile, NatsNKeyFile, NatsToken and NatsUser authenticates the
+	// connection. A negative NatsMaxReconnects reconnects forever.
+	NatsURLs          []string      `env:"NATS_URLS" env-default:"nats://localhost:4222" env-separator:","`
+	NatsUser          string        `env:"NATS_USER"`
+	NatsPassword      string        `env:"NATS_PASSWORD"`
+	NatsToken         string        `env:"NATS_TOKEN"`
+	NatsNKeyFile      string        `env:"NATS_NKEY_FILE"`
+	NatsCredsFile     string        `env:"NATS_CREDS_FILE"`
+	NatsTLSCAFile     string        `env:"NATS_TLS_CA_FILE"`
+	NatsTLSCertFile   string        `env:"NATS_TLS_CERT_FILE"`
+	NatsTLSKeyFile    string        `env:"NATS_TLS_KEY_FILE"`
+	NatsReconnectWait time.Duration `env:"NATS_RECONNECT_WAIT" env-default:"2s"`
+	NatsMaxReconnects int           `env:"NATS_MAX_RECONNECTS" env-default:"-1"`
+
+	// NatsJetStream publishes events to the NatsStream stream, deduplicated by
+	// event id within NatsDuplicateWindow, instead of core NATS.
+	NatsJetStream       bool          `env:"NATS_JETSTREAM" env-default:"false"`
+	NatsStream          string        `env:"NATS_STREAM" env-default:"GOODS_LOGS"`
+	NatsDuplicateWindow time.Duration `env:"NATS_DUPLICATE_WINDOW" env-default:"10m"`
+
+	// IdempotencyTTL is how long responses to requests with an Idempotency-Key
+	// are kept for replay. IdempotencyPendingTTL is how long the key stays
+	// reserved for a request in progress, so a key left behind by a crashed
+	// request can be retried soon.
+	IdempotencyTTL        time.Duration `env:"IDEMPOTENCY_TTL" env-default:"24h"`
+	IdempotencyPendingTTL time.Duration `env:"IDEMPOTENCY_PENDING_TTL" env-default:"1m"`
+
+	// RankingMode selects how goods are ordered: "integer" shifts the priority
+	// of every good in between on a move, "lexorank" rewrites only the rank of
+	// the moved good and derives priority from the rank order. Ranks are kept
+	// in both modes, priorities only in the integer one, so switching from
+	// lexorank back to integer on live data is not supported.
+	RankingMode           string        `env:"RANKING_MODE" env-default:"integer"`
+	RankMaxLength         int           `env:"RANK_MAX_LENGTH" env-default:"16"`
+	RankRebalanceInterval time.Duration `env:"RANK_REBALANCE_INTERVAL" env-default:"5m"`
+
+	PurgeAfter     time.Duration `env:"PURGE_AFTER" env-default:"720h"`
+	PurgeInterval  time.Duration `env:"PURGE_INTERVAL" env-default:"1h"`
+	PurgeBatchSize int           `env:"PURGE_BATCH_SIZE" env-default:"500"`
+
+	// Events are stored in the postgres outbox with the change that caused them
+	// and relayed to nats every OutboxRelayInterval. A failed publish is retried
+	// after OutboxRetryDelay, doubled on every further failure up to
+	// OutboxMaxRetryDelay. Sent events are deleted by the purger once they are
+	// older than OutboxRetention.
+	OutboxRelayInterval time.Duration `env:"OUTBOX_RELAY_INTERVAL" env-default:"1s"`
+	OutboxBatchSize     int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	OutboxRetryDelay    time.Duration `env:"OUTBOX_RETRY_DELAY" env-default:"1s"`
+	OutboxMaxRetryDelay time.Duration `env:"OUTBOX_MAX_RETRY_DELAY" env-default:"5m"`
+	OutboxRetention     time.Duration `env:"OUTBOX_RETENTION" env-default:"24h"`
 }
 
 func New() *Config {
@@ -33,5 +91,29 @@ func New() *Config {
 		panic("error getting config")
 	}
 
+	if err = cfg.validate(); err != nil {
+		panic("invalid config: " + err.Error())
+	}
+
 	return &cfg
 }
+
+// validate rejects values that would make the background jobs spin or panic.
+func (c *Config) validate() error {
+	switch {
+	case c.OutboxRelayInterval <= 0:
+		return errors.New("OUTBOX_RELAY_INTERVAL must be positive")
+	case c.OutboxBatchSize <= 0:
+		return errors.New("OUTBOX_BATCH_SIZE must be positive")
+	case c.PurgeInterval <= 0:
+		return errors.New("PURGE_INTERVAL must be positive")
+	case c.PurgeBatchSize <= 0:
+		return errors.New("PURGE_BATCH_SIZE must be positive")
+	case c.RankRebalanceInterval <= 0:
+		return errors.New("RANK_REBALANCE_INTERVAL must be positive")
+	case c.RankMaxLength <= 0:
+		return errors.New("RANK_MAX_LENGTH must be positive")
+	}
+
+	return nil
+}
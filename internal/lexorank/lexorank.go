@@ -0,0 +1,94 @@
+// Package lexorank generates string ranks that sort lexicographically (byte
+// order, "C" collation), so a good can be moved between two others by
+// rewriting only its own rank.
+package lexorank
+
+import (
+	"errors"
+	"strings"
+)
+
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+const base = len(alphabet)
+
+var ErrInvalidRange = errors.New("no rank fits between the given ranks")
+
+// Between returns a rank strictly between prev and next. An empty prev means
+// the beginning of the list and an empty next means its end.
+func Between(prev, next string) (string, error) {
+	if next != "" && prev >= next {
+		return "", ErrInvalidRange
+	}
+
+	var result strings.Builder
+
+	bounded := next != ""
+
+	for i := 0; ; i++ {
+		// the result already equals next, nothing can be placed below it
+		if bounded && i >= len(next) {
+			return "", ErrInvalidRange
+		}
+
+		low := digit(prev, i, 0)
+
+		high := base
+		if bounded {
+			high = digit(next, i, 0)
+		}
+
+		if high-low > 1 {
+			result.WriteByte(alphabet[(low+high)/2])
+
+			return result.String(), nil
+		}
+
+		result.WriteByte(alphabet[low])
+
+		// once the result is below next, only prev limits the remaining digits
+		if low < high {
+			bounded = false
+		}
+	}
+}
+
+// Spread returns n evenly spaced ranks of equal length in ascending order,
+// leaving room for insertions between every pair of neighbours.
+func Spread(n int) []string {
+	length := 1
+	capacity := base
+
+	for capacity <= (n+1)*base {
+		length++
+		capacity *= base
+	}
+
+	step := capacity / (n + 1)
+	ranks := make([]string, 0, n)
+
+	for i := 1; i <= n; i++ {
+		ranks = append(ranks, encode(i*step, length))
+	}
+
+	return ranks
+}
+
+func digit(rank string, position int, fallback int) int {
+	if position >= len(rank) {
+		return fallback
+	}
+
+	return strings.IndexByte(alphabet, rank[position])
+}
+
+func encode(value int, length int) string {
+	encoded := make([]byte, length)
+
+	for i := length - 1; i >= 0; i-- {
+		encoded[i] = alphabet[value%base]
+		value /= base
+	}
+
+	return string(encoded)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// Cursor points at the last good of a page in (projectId, priority, id) order
+// and is handed to clients as an opaque string.
+type Cursor struct {
+	ProjectID int64
+	Priority  int
+	ID        int64
+}
+
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.ProjectID, 10) + ":" + strconv.Itoa(c.Priority) + ":" + strconv.FormatInt(c.ID, 10)
+
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+
+	projectID, priority, id := parts[0], parts[1], parts[2]
+
+	var result Cursor
+
+	if result.ProjectID, err = strconv.ParseInt(projectID, 10, 64); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if result.Priority, err = strconv.Atoi(priority); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if result.ID, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &result, nil
+}
@@ -1,9 +1,39 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrBlankName     = errors.New("name is blank")
-	ErrWrongPriority = errors.New("priority is less than 0")
-	ErrGoodNotFound  = errors.New("good not found")
+	ErrGoodNotFound    = errors.New("good not found")
+	ErrProjectNotFound = errors.New("project not found")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+	ErrWrongReorder    = errors.New("ids are empty or contain duplicates")
+	ErrWrongBulkFilter = errors.New("exactly one of ids or a non-empty filter is required")
+	ErrEmptyBulkUpdate = errors.New("nothing to update")
+	ErrEmptyBatch      = errors.New("batch has no operations")
+	ErrUnknownBatchOp  = errors.New("unknown batch operation")
+	ErrWrongBatchRef   = errors.New("ref does not point to an earlier operation with a good")
+	ErrVersionMismatch = errors.New("good has been changed since it was read")
+
+	ErrConflict = errors.New("conflicting concurrent change")
+
+	ErrIdempotencyKeyReused  = errors.New("idempotency key is reused with a different request")
+	ErrIdempotencyInProgress = errors.New("request with the idempotency key is in progress")
 )
+
+// BatchError reports the operation of a batch that failed and caused the
+// whole batch to be rolled back.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("operation %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
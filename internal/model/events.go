@@ -0,0 +1,93 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventTypeCreated       = "created"
+	EventTypeUpdated       = "updated"
+	EventTypeRemoved       = "removed"
+	EventTypeRestored      = "restored"
+	EventTypeReprioritized = "reprioritized"
+	EventTypePurged        = "purged"
+)
+
+// EventSchemaVersion is the version of the GoodsEvent layout. Version 1 was a
+// good with the type and time of the event added to it.
+const EventSchemaVersion = 2
+
+// GoodsEvent describes a change of a good. Previous is nil for a created good
+// and New is nil for a purged one.
+type GoodsEvent struct {
+	EventID       uuid.UUID `json:"eventId"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schemaVersion"`
+	EventTime     time.Time `json:"eventTime"`
+	Previous      *Goods    `json:"previous,omitempty"`
+	New           *Goods    `json:"new,omitempty"`
+}
+
+func NewGoodsEvent(eventType string, previous, current *Goods) GoodsEvent {
+	return GoodsEvent{
+		EventID:       uuid.New(),
+		Type:          eventType,
+		SchemaVersion: EventSchemaVersion,
+		EventTime:     time.Now(),
+		Previous:      previous,
+		New:           current,
+	}
+}
+
+// Goods returns the latest known state of the changed good.
+func (e GoodsEvent) Goods() Goods {
+	if e.New != nil {
+		return *e.New
+	}
+
+	if e.Previous != nil {
+		return *e.Previous
+	}
+
+	return Goods{}
+}
+
+// UnmarshalJSON also reads events of schema version 1, so events published
+// before an upgrade are not lost. They get a nil event id.
+func (e *GoodsEvent) UnmarshalJSON(data []byte) error {
+	type envelope GoodsEvent
+
+	var event envelope
+	if err := json.Unmarshal(data, &event); err != nil {
+		return fmt.Errorf("json.Unmarshal(data, &event): %w", err)
+	}
+
+	if event.SchemaVersion != 0 {
+		*e = GoodsEvent(event)
+
+		return nil
+	}
+
+	var legacy struct {
+		Goods
+		Type      string    `json:"type"`
+		EventTime time.Time `json:"eventTime"`
+	}
+
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return fmt.Errorf("json.Unmarshal(data, &legacy): %w", err)
+	}
+
+	*e = GoodsEvent{
+		Type:          legacy.Type,
+		SchemaVersion: 1,
+		EventTime:     legacy.EventTime,
+		New:           &legacy.Goods,
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"time"
@@ -16,37 +17,238 @@ type Goods struct {
 	Priority    int        `json:"priority,omitempty"`
 	Removed     bool       `json:"removed"`
 	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	RemovedAt   *time.Time `json:"removedAt,omitempty"`
+	Version     int        `json:"version,omitempty" schema:"-"`
 }
 
-type GoodsEvent struct {
-	Goods
-	EventTime time.Time `json:"eventTime"`
+// OutboxMessage is an event stored with the change that caused it and not yet
+// published.
+type OutboxMessage struct {
+	ID       int64
+	Event    GoodsEvent
+	Attempts int
 }
 
 type Project struct {
-	ID        int64     `json:"id"`
+	ID        int64     `json:"id" schema:"id"`
 	Name      string    `json:"name"`
+	Removed   bool      `json:"removed"`
 	CreatedAt time.Time `json:"createdAt"`
 }
 
+type UpdateProjectRequest struct {
+	ID   int64  `json:"id" schema:"id"`
+	Name string `json:"name"`
+}
+
+type ProjectListParams struct {
+	Limit  int `json:"limit,omitempty" schema:"limit"`
+	Offset int `json:"offset,omitempty" schema:"offset"`
+}
+
+// UpdateGoodsRequest is a JSON merge patch (RFC 7396) of a good. Absent fields
+// are left as they are, a null description clears it.
 type UpdateGoodsRequest struct {
 	ID          int64   `json:"id"`
 	ProjectID   int64   `json:"projectId"`
-	Name        string  `json:"name"`
-	Description *string `json:"description"`
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Priority    *int    `json:"priority,omitempty"`
+	// Version is the expected version of the good, zero skips the check.
+	Version int `json:"-" schema:"-"`
+}
+
+func (r *UpdateGoodsRequest) UnmarshalJSON(data []byte) error {
+	var patch struct {
+		ID          int64           `json:"id"`
+		ProjectID   int64           `json:"projectId"`
+		Name        json.RawMessage `json:"name"`
+		Description json.RawMessage `json:"description"`
+		Priority    json.RawMessage `json:"priority"`
+	}
+
+	if err := json.Unmarshal(data, &patch); err != nil {
+		return fmt.Errorf("json.Unmarshal(data, &patch): %w", err)
+	}
+
+	r.ID = patch.ID
+	r.ProjectID = patch.ProjectID
+
+	// null cannot be stored as a name or a priority, so it is turned into a
+	// value that fails validation
+	if patch.Name != nil {
+		r.Name = new(string)
+		if err := unmarshalNullable(patch.Name, r.Name); err != nil {
+			return fmt.Errorf("unmarshalNullable(patch.Name, r.Name): %w", err)
+		}
+	}
+
+	if patch.Description != nil {
+		r.Description = new(string)
+		if err := unmarshalNullable(patch.Description, r.Description); err != nil {
+			return fmt.Errorf("unmarshalNullable(patch.Description, r.Description): %w", err)
+		}
+	}
+
+	if patch.Priority != nil {
+		r.Priority = new(int)
+		if err := unmarshalNullable(patch.Priority, r.Priority); err != nil {
+			return fmt.Errorf("unmarshalNullable(patch.Priority, r.Priority): %w", err)
+		}
+	}
+
+	return nil
+}
+
+// unmarshalNullable leaves target at its zero value for a JSON null.
+func unmarshalNullable(data json.RawMessage, target any) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	return json.Unmarshal(data, target)
 }
 
 type UpdatePriorityRequest struct {
 	ID        int64 `json:"id" schema:"id"`
 	ProjectID int64 `json:"projectId"`
 	Priority  int   `json:"newPriority"`
+	Version   int   `json:"-" schema:"-"`
 }
 
+type BulkCreateRequest struct {
+	ProjectID int64   `json:"projectId" schema:"projectId"`
+	Goods     []Goods `json:"goods"`
+}
+
+type BulkItemResult struct {
+	Index  int              `json:"index"`
+	Goods  *Goods           `json:"goods,omitempty"`
+	Error  string           `json:"error,omitempty"`
+	Errors ValidationErrors `json:"errors,omitempty"`
+}
+
+// BulkFilter selects the goods of a bulk operation by name prefix and
+// creation time.
+type BulkFilter struct {
+	NamePrefix    string     `json:"namePrefix,omitempty"`
+	CreatedBefore *time.Time `json:"createdBefore,omitempty"`
+}
+
+func (f *BulkFilter) IsEmpty() bool {
+	return f.NamePrefix == "" && f.CreatedBefore == nil
+}
+
+type BulkRemoveRequest struct {
+	ProjectID int64       `json:"projectId" schema:"projectId"`
+	IDs       []int64     `json:"ids,omitempty" schema:"-"`
+	Filter    *BulkFilter `json:"filter,omitempty" schema:"-"`
+	DryRun    bool        `json:"dryRun" schema:"dryRun"`
+}
+
+// BulkUpdateSet lists the changes of a bulk update. NamePattern may refer to
+// the current name and id of a good as {name} and {id}.
+type BulkUpdateSet struct {
+	Description *string `json:"description,omitempty"`
+	NamePattern *string `json:"namePattern,omitempty"`
+}
+
+type BulkUpdateRequest struct {
+	ProjectID int64         `json:"projectId" schema:"projectId"`
+	IDs       []int64       `json:"ids,omitempty" schema:"-"`
+	Filter    *BulkFilter   `json:"filter,omitempty" schema:"-"`
+	Set       BulkUpdateSet `json:"set" schema:"-"`
+	DryRun    bool          `json:"dryRun" schema:"dryRun"`
+}
+
+const (
+	BatchOpCreate       = "create"
+	BatchOpUpdate       = "update"
+	BatchOpRemove       = "remove"
+	BatchOpReprioritize = "reprioritize"
+)
+
+// BatchOperation is one step of a batch. Ref points to an earlier operation of
+// the same batch whose good is used instead of ID and ProjectID. An update
+// changes only the fields that are set.
+type BatchOperation struct {
+	Op          string  `json:"op"`
+	ID          int64   `json:"id,omitempty"`
+	Ref         *int    `json:"ref,omitempty"`
+	ProjectID   int64   `json:"projectId,omitempty"`
+	Name        string  `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Priority    int     `json:"newPriority,omitempty"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchResult struct {
+	Index      int      `json:"index"`
+	Op         string   `json:"op"`
+	Goods      *Goods   `json:"goods,omitempty"`
+	Priorities *[]Goods `json:"priorities,omitempty"`
+}
+
+// IdempotencyRecord is the stored outcome of a request made with an
+// Idempotency-Key. A record without a status code belongs to a request that is
+// still being processed.
+type IdempotencyRecord struct {
+	RequestHash string            `json:"requestHash"`
+	StatusCode  int               `json:"statusCode,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+}
+
+type ReorderRequest struct {
+	ProjectID int64   `json:"projectId" schema:"projectId"`
+	IDs       []int64 `json:"ids"`
+}
+
+const (
+	SortPriority     = "priority"
+	SortPriorityDesc = "-priority"
+	SortCreatedAt    = "createdAt"
+	SortName         = "name"
+
+	NameMatchPrefix   = "prefix"
+	NameMatchContains = "contains"
+)
+
 type ListParams struct {
-	Limit   int `json:"limit,omitempty" schema:"limit"`
-	Offset  int `json:"offset,omitempty" schema:"offset"`
-	Total   int `json:"total,omitempty"`
-	Removed int `json:"removed,omitempty"`
+	Limit          int        `json:"limit,omitempty" schema:"limit"`
+	Offset         int        `json:"offset,omitempty" schema:"offset"`
+	ProjectID      int64      `json:"projectId,omitempty" schema:"projectId"`
+	Name           string     `json:"name,omitempty" schema:"name"`
+	NameMatch      string     `json:"nameMatch,omitempty" schema:"nameMatch"`
+	PriorityFrom   int        `json:"priorityFrom,omitempty" schema:"priorityFrom"`
+	PriorityTo     int        `json:"priorityTo,omitempty" schema:"priorityTo"`
+	CreatedFrom    *time.Time `json:"createdFrom,omitempty" schema:"createdFrom"`
+	CreatedTo      *time.Time `json:"createdTo,omitempty" schema:"createdTo"`
+	IncludeRemoved bool       `json:"includeRemoved,omitempty" schema:"includeRemoved"`
+	Sort           string     `json:"sort,omitempty" schema:"sort"`
+	Cursor         string     `json:"cursor,omitempty" schema:"cursor"`
+	Total          int        `json:"total,omitempty" schema:"-"`
+	Active         int        `json:"active,omitempty" schema:"-"`
+	Removed        int        `json:"removed,omitempty" schema:"-"`
+	HasMore        bool       `json:"hasMore" schema:"-"`
+	NextCursor     string     `json:"nextCursor,omitempty" schema:"-"`
+}
+
+func (p ListParams) IsPrioritySort() bool {
+	return p.Sort == "" || p.Sort == SortPriority || p.Sort == SortPriorityDesc
+}
+
+// WithDefaults returns the params with an omitted limit set to
+// DefaultListLimit, so that a page is never empty while more goods follow.
+func (p ListParams) WithDefaults() ListParams {
+	if p.Limit == 0 {
+		p.Limit = DefaultListLimit
+	}
+
+	return p
 }
 
 type GetListResponse struct {
@@ -0,0 +1,279 @@
+package model
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of field violations reported in ValidationErrors.
+const (
+	CodeRequired     = "required"
+	CodeTooLong      = "tooLong"
+	CodeOutOfRange   = "outOfRange"
+	CodeUnknownValue = "unknownValue"
+	CodeInvalid      = "invalid"
+	CodeNotFound     = "notFound"
+	CodeUnsupported  = "unsupported"
+)
+
+const (
+	MaxNameLength        = 255
+	MaxDescriptionLength = 4096
+	MaxListLimit         = 1000
+	DefaultListLimit     = 100
+)
+
+var ErrValidation = errors.New("validation failed")
+
+// ValidationErrors maps request fields, named as in JSON and query strings, to
+// the code of their violation. It matches ErrValidation.
+type ValidationErrors map[string]string
+
+// Add records the violation unless the field already has one.
+func (e ValidationErrors) Add(field, code string) {
+	if _, ok := e[field]; !ok {
+		e[field] = code
+	}
+}
+
+// Err returns the violations as an error or nil when there are none.
+func (e ValidationErrors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
+
+func (e ValidationErrors) Error() string {
+	fields := make([]string, 0, len(e))
+	for field, code := range e {
+		fields = append(fields, field+": "+code)
+	}
+
+	sort.Strings(fields)
+
+	return ErrValidation.Error() + ": " + strings.Join(fields, ", ")
+}
+
+func (e ValidationErrors) Is(target error) bool {
+	return target == ErrValidation //nolint: errorlint
+}
+
+func (e ValidationErrors) checkName(field, name string) {
+	switch {
+	case name == "":
+		e.Add(field, CodeRequired)
+	case utf8.RuneCountInString(name) > MaxNameLength:
+		e.Add(field, CodeTooLong)
+	}
+}
+
+func (e ValidationErrors) checkDescription(field, description string) {
+	if utf8.RuneCountInString(description) > MaxDescriptionLength {
+		e.Add(field, CodeTooLong)
+	}
+}
+
+func (e ValidationErrors) checkRequiredID(field string, id int64) {
+	if id == 0 {
+		e.Add(field, CodeRequired)
+	}
+}
+
+// Validate checks a project that is about to be created.
+func (p Project) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkName("name", p.Name)
+
+	return violations
+}
+
+func (r UpdateProjectRequest) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkName("name", r.Name)
+
+	return violations
+}
+
+// Validate checks a good that is about to be created.
+func (g Goods) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkRequiredID("projectId", g.ProjectID)
+	violations.checkName("name", g.Name)
+	violations.checkDescription("description", g.Description)
+
+	return violations
+}
+
+func (r UpdateGoodsRequest) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkRequiredID("id", r.ID)
+	violations.checkRequiredID("projectId", r.ProjectID)
+
+	if r.Name != nil {
+		violations.checkName("name", *r.Name)
+	}
+
+	if r.Description != nil {
+		violations.checkDescription("description", *r.Description)
+	}
+
+	if r.Priority != nil && *r.Priority < 1 {
+		violations.Add("priority", CodeOutOfRange)
+	}
+
+	return violations
+}
+
+// Validate checks the changes of a bulk update. The names made from the
+// pattern are checked by ValidateNames once the matching goods are known.
+func (r BulkUpdateRequest) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	if r.Set.NamePattern != nil {
+		violations.checkName("set.namePattern", *r.Set.NamePattern)
+	}
+
+	if r.Set.Description != nil {
+		violations.checkDescription("set.description", *r.Set.Description)
+	}
+
+	return violations
+}
+
+// ValidateNames checks the names the pattern of the update gave the goods.
+func (r BulkUpdateRequest) ValidateNames(goods []Goods) ValidationErrors {
+	violations := make(ValidationErrors)
+
+	if r.Set.NamePattern == nil {
+		return violations
+	}
+
+	for _, good := range goods {
+		violations.checkName("set.namePattern", good.Name)
+	}
+
+	return violations
+}
+
+func (r UpdatePriorityRequest) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkRequiredID("id", r.ID)
+	violations.checkRequiredID("projectId", r.ProjectID)
+
+	if r.Priority < 1 {
+		violations.Add("newPriority", CodeOutOfRange)
+	}
+
+	return violations
+}
+
+func (r ReorderRequest) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkRequiredID("projectId", r.ProjectID)
+
+	return violations
+}
+
+func (p ProjectListParams) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	if p.Limit < 0 || p.Limit > MaxListLimit {
+		violations.Add("limit", CodeOutOfRange)
+	}
+
+	if p.Offset < 0 {
+		violations.Add("offset", CodeOutOfRange)
+	}
+
+	return violations
+}
+
+func (p ListParams) Validate() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	if p.Limit < 0 || p.Limit > MaxListLimit {
+		violations.Add("limit", CodeOutOfRange)
+	}
+
+	if p.Offset < 0 {
+		violations.Add("offset", CodeOutOfRange)
+	}
+
+	if utf8.RuneCountInString(p.Name) > MaxNameLength {
+		violations.Add("name", CodeTooLong)
+	}
+
+	if p.PriorityFrom < 0 {
+		violations.Add("priorityFrom", CodeOutOfRange)
+	}
+
+	if p.PriorityTo < 0 || (p.PriorityTo != 0 && p.PriorityTo < p.PriorityFrom) {
+		violations.Add("priorityTo", CodeOutOfRange)
+	}
+
+	if p.CreatedFrom != nil && p.CreatedTo != nil && p.CreatedTo.Before(*p.CreatedFrom) {
+		violations.Add("createdTo", CodeOutOfRange)
+	}
+
+	switch p.Sort {
+	case "", SortPriority, SortPriorityDesc, SortCreatedAt, SortName:
+	default:
+		violations.Add("sort", CodeUnknownValue)
+	}
+
+	switch p.NameMatch {
+	case "", NameMatchPrefix, NameMatchContains:
+	default:
+		violations.Add("nameMatch", CodeUnknownValue)
+	}
+
+	if p.Cursor != "" {
+		if _, err := DecodeCursor(p.Cursor); err != nil || !p.IsPrioritySort() {
+			violations.Add("cursor", CodeInvalid)
+		}
+	}
+
+	return violations
+}
+
+// ValidateTrash validates the params of the trash of a project, which is
+// ordered by removal time and paged by offset. Sorting, cursors and the
+// filters of the list are rejected rather than ignored.
+func (p ListParams) ValidateTrash() ValidationErrors {
+	violations := make(ValidationErrors)
+
+	violations.checkRequiredID("projectId", p.ProjectID)
+
+	unsupported := map[string]bool{
+		"sort":         p.Sort != "",
+		"cursor":       p.Cursor != "",
+		"name":         p.Name != "",
+		"nameMatch":    p.NameMatch != "",
+		"priorityFrom": p.PriorityFrom != 0,
+		"priorityTo":   p.PriorityTo != 0,
+		"createdFrom":  p.CreatedFrom != nil,
+		"createdTo":    p.CreatedTo != nil,
+	}
+
+	for field, set := range unsupported {
+		if set {
+			violations.Add(field, CodeUnsupported)
+		}
+	}
+
+	for field, code := range p.Validate() {
+		violations.Add(field, code)
+	}
+
+	return violations
+}
@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"go.uber.org/zap"
+)
+
+// ExecuteBatch runs the operations in order inside one transaction, each with
+// the store and outbox part of the service method of the operation. The first
+// failing operation rolls the whole batch back and is reported as
+// model.BatchError without any results. The cache is invalidated once the
+// batch is committed.
+func (s *Service) ExecuteBatch(ctx context.Context, request model.BatchRequest) (*[]model.BatchResult, error) {
+	if len(request.Operations) == 0 {
+		return nil, model.ErrEmptyBatch
+	}
+
+	results := make([]model.BatchResult, 0, len(request.Operations))
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		for i, operation := range request.Operations {
+			result := model.BatchResult{
+				Index: i,
+				Op:    operation.Op,
+			}
+
+			if err := s.executeOperation(ctx, operation, results, &result); err != nil {
+				return &model.BatchError{Index: i, Err: err}
+			}
+
+			results = append(results, result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("ExecuteBatch/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return &results, nil
+}
+
+// executeOperation fills result with the outcome of the operation. The
+// operations join the transaction of the batch through ctx and leave the cache
+// to ExecuteBatch.
+func (s *Service) executeOperation(
+	ctx context.Context,
+	operation model.BatchOperation,
+	previous []model.BatchResult,
+	result *model.BatchResult,
+) error {
+	if operation.Ref != nil {
+		if *operation.Ref < 0 || *operation.Ref >= len(previous) || previous[*operation.Ref].Goods == nil {
+			return model.ErrWrongBatchRef
+		}
+
+		operation.ID = previous[*operation.Ref].Goods.ID
+		operation.ProjectID = previous[*operation.Ref].Goods.ProjectID
+	}
+
+	var err error
+
+	switch operation.Op {
+	case model.BatchOpCreate:
+		goods := model.Goods{
+			ProjectID: operation.ProjectID,
+			Name:      operation.Name,
+		}
+		if operation.Description != nil {
+			goods.Description = *operation.Description
+		}
+
+		result.Goods, err = s.createGoods(ctx, goods)
+		if err != nil {
+			return fmt.Errorf("s.createGoods(ctx, goods): %w", err)
+		}
+	case model.BatchOpUpdate:
+		request := model.UpdateGoodsRequest{
+			ID:          operation.ID,
+			ProjectID:   operation.ProjectID,
+			Description: operation.Description,
+		}
+
+		if operation.Name != "" {
+			request.Name = &operation.Name
+		}
+
+		if operation.Priority != 0 {
+			request.Priority = &operation.Priority
+		}
+
+		result.Goods, err = s.updateGoods(ctx, request)
+		if err != nil {
+			return fmt.Errorf("s.updateGoods(ctx, request): %w", err)
+		}
+	case model.BatchOpRemove:
+		result.Goods, err = s.deleteGoods(ctx, model.Goods{
+			ID:        operation.ID,
+			ProjectID: operation.ProjectID,
+		})
+		if err != nil {
+			return fmt.Errorf("s.deleteGoods(...): %w", err)
+		}
+	case model.BatchOpReprioritize:
+		result.Priorities, err = s.reprioritizeGoods(ctx, model.UpdatePriorityRequest{
+			ID:        operation.ID,
+			ProjectID: operation.ProjectID,
+			Priority:  operation.Priority,
+		})
+		if err != nil {
+			return fmt.Errorf("s.reprioritizeGoods(...): %w", err)
+		}
+	default:
+		return model.ErrUnknownBatchOp
+	}
+
+	return nil
+}
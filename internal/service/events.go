@@ -0,0 +1,48 @@
+package service
+
+import "github.com/Saaghh/hezzl-hr/internal/model"
+
+// changeEvents builds an event for every changed good with its state in
+// previousGoods as the previous value.
+func changeEvents(eventType string, previousGoods map[int64]model.Goods, changed []model.Goods) []model.GoodsEvent {
+	events := make([]model.GoodsEvent, 0, len(changed))
+
+	for i := range changed {
+		current := changed[i]
+
+		var previous *model.Goods
+		if value, ok := previousGoods[current.ID]; ok {
+			previous = &value
+		}
+
+		events = append(events, model.NewGoodsEvent(eventType, previous, &current))
+	}
+
+	return events
+}
+
+// createEvents builds an event for every created good.
+func createEvents(created []model.Goods) []model.GoodsEvent {
+	return changeEvents(model.EventTypeCreated, nil, created)
+}
+
+// updateEvents reports the updated good and the goods moved by a priority
+// change of the update.
+func updateEvents(previousGoods map[int64]model.Goods, result *model.Goods, moved *[]model.Goods) []model.GoodsEvent {
+	changed := updatedGoods(result, moved)
+	events := changeEvents(model.EventTypeUpdated, previousGoods, changed[:1])
+
+	return append(events, changeEvents(model.EventTypeReprioritized, previousGoods, changed[1:])...)
+}
+
+// purgeEvents builds an event for every purged good, which has no new value.
+func purgeEvents(purged []model.Goods) []model.GoodsEvent {
+	events := make([]model.GoodsEvent, 0, len(purged))
+
+	for i := range purged {
+		previous := purged[i]
+		events = append(events, model.NewGoodsEvent(model.EventTypePurged, &previous, nil))
+	}
+
+	return events
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+// ReserveIdempotencyKey claims the key for a request. It returns nil when the
+// request has to be executed and the stored record when it has to be
+// replayed.
+func (s *Service) ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	record, err := s.cash.ReserveIdempotencyKey(ctx, key, requestHash)
+	if err != nil {
+		return nil, fmt.Errorf("s.cash.ReserveIdempotencyKey(ctx, key, requestHash): %w", err)
+	}
+
+	switch {
+	case record == nil:
+		return nil, nil
+	case record.RequestHash != requestHash:
+		return nil, model.ErrIdempotencyKeyReused
+	case record.StatusCode == 0:
+		return nil, model.ErrIdempotencyInProgress
+	}
+
+	return record, nil
+}
+
+func (s *Service) StoreIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord) error {
+	if err := s.cash.StoreIdempotencyRecord(ctx, key, record); err != nil {
+		return fmt.Errorf("s.cash.StoreIdempotencyRecord(ctx, key, record): %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees the key of a request that failed so that it can
+// be retried.
+func (s *Service) ReleaseIdempotencyKey(ctx context.Context, key string) error {
+	if err := s.cash.DeleteIdempotencyKey(ctx, key); err != nil {
+		return fmt.Errorf("s.cash.DeleteIdempotencyKey(ctx, key): %w", err)
+	}
+
+	return nil
+}
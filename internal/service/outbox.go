@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"go.uber.org/zap"
+)
+
+// enqueueEvents stores the events in the outbox. Called with a context of
+// WithTx, the events are published only if the transaction commits.
+func (s *Service) enqueueEvents(ctx context.Context, events []model.GoodsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := s.db.AddOutboxEvents(ctx, events); err != nil {
+		return fmt.Errorf("s.db.AddOutboxEvents(ctx, events): %w", err)
+	}
+
+	return nil
+}
+
+// wakeRelay makes the outbox relay run without waiting for its next tick.
+func (s *Service) wakeRelay() {
+	select {
+	case s.relayWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// RunOutboxRelay publishes the events stored in the outbox to the broker,
+// batchSize at a time, every interval and whenever a change is committed.
+// Failed publishes are retried with a delay doubling from retryDelay up to
+// maxRetryDelay, so every event is delivered at least once. It blocks until
+// ctx is done.
+func (s *Service) RunOutboxRelay(ctx context.Context, interval time.Duration, batchSize int, retryDelay, maxRetryDelay time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.relayWakeup:
+		}
+
+		for ctx.Err() == nil {
+			relayed, err := s.relayOutbox(ctx, batchSize, retryDelay, maxRetryDelay)
+			if err != nil {
+				zap.L().With(zap.Error(err)).Warn("RunOutboxRelay/s.relayOutbox(...)")
+
+				break
+			}
+
+			if relayed < batchSize {
+				break
+			}
+		}
+	}
+}
+
+// relayOutbox publishes one batch of due outbox messages and returns how many
+// were taken from the outbox. Nothing is taken while the broker is
+// disconnected.
+func (s *Service) relayOutbox(ctx context.Context, batchSize int, retryDelay, maxRetryDelay time.Duration) (int, error) {
+	var relayed int
+
+	// messages wait for the broker instead of using up their retries
+	if !s.bl.IsConnected() {
+		return 0, nil
+	}
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		messages, err := s.db.GetPendingOutbox(ctx, batchSize)
+		if err != nil {
+			return fmt.Errorf("s.db.GetPendingOutbox(ctx, batchSize): %w", err)
+		}
+
+		relayed = len(*messages)
+		published := make([]model.OutboxMessage, 0, len(*messages))
+
+		for _, message := range *messages {
+			if err = s.bl.PublishEvent(message.Event); err != nil {
+				zap.L().With(zap.Error(err), zap.Int64("id", message.ID)).Warn("relayOutbox/s.bl.PublishEvent(message.Event)")
+
+				if err = s.markOutboxFailed(ctx, message, err, retryDelay, maxRetryDelay); err != nil {
+					return fmt.Errorf("s.markOutboxFailed(...): %w", err)
+				}
+
+				continue
+			}
+
+			published = append(published, message)
+		}
+
+		if len(published) == 0 {
+			return nil
+		}
+
+		// published messages may still be buffered by the client and lost with
+		// the connection
+		if err = s.bl.Flush(); err != nil {
+			zap.L().With(zap.Error(err)).Warn("relayOutbox/s.bl.Flush()")
+
+			for _, message := range published {
+				if err = s.markOutboxFailed(ctx, message, err, retryDelay, maxRetryDelay); err != nil {
+					return fmt.Errorf("s.markOutboxFailed(...): %w", err)
+				}
+			}
+
+			return nil
+		}
+
+		sent := make([]int64, 0, len(published))
+		for _, message := range published {
+			sent = append(sent, message.ID)
+		}
+
+		if err = s.db.MarkOutboxSent(ctx, sent); err != nil {
+			return fmt.Errorf("s.db.MarkOutboxSent(ctx, sent): %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	return relayed, nil
+}
+
+// markOutboxFailed postpones the next attempt of the message by its retry
+// delay.
+func (s *Service) markOutboxFailed(ctx context.Context, message model.OutboxMessage, cause error, retryDelay, maxRetryDelay time.Duration) error {
+	nextAttempt := time.Now().Add(outboxRetryDelay(message.Attempts, retryDelay, maxRetryDelay))
+
+	if err := s.db.MarkOutboxFailed(ctx, message.ID, cause.Error(), nextAttempt); err != nil {
+		return fmt.Errorf("s.db.MarkOutboxFailed(...): %w", err)
+	}
+
+	return nil
+}
+
+// pruneOutbox deletes the messages sent before sentBefore, batchSize at a
+// time, and returns how many were deleted.
+func (s *Service) pruneOutbox(ctx context.Context, sentBefore time.Time, batchSize int) (int, error) {
+	var pruned int
+
+	for ctx.Err() == nil {
+		deleted, err := s.db.DeleteSentOutbox(ctx, sentBefore, batchSize)
+		if err != nil {
+			return pruned, fmt.Errorf("s.db.DeleteSentOutbox(ctx, sentBefore, batchSize): %w", err)
+		}
+
+		pruned += deleted
+
+		if deleted < batchSize {
+			break
+		}
+	}
+
+	return pruned, nil
+}
+
+// outboxRetryDelay doubles retryDelay for every failed attempt, up to
+// maxRetryDelay.
+func outboxRetryDelay(attempts int, retryDelay, maxRetryDelay time.Duration) time.Duration {
+	delay := retryDelay
+
+	for i := 0; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxRetryDelay)
+}
@@ -15,38 +15,80 @@ type Service struct {
 	db   store
 	cash cashdb
 	bl   brokerLogger
+
+	relayWakeup chan struct{}
 }
 
 type cashdb interface {
-	StoreGetResponse(ctx context.Context, response model.GetListResponse) error
+	StoreGetResponse(ctx context.Context, params model.ListParams, response model.GetListResponse) error
 	InvalidateAllData(ctx context.Context) error
 	GetListResponse(ctx context.Context, params model.ListParams) (*model.GetListResponse, error)
+	StoreGood(ctx context.Context, goods model.Goods) error
+	GetGood(ctx context.Context, goods model.Goods) (*model.Goods, error)
+
+	ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*model.IdempotencyRecord, error)
+	StoreIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord) error
+	DeleteIdempotencyKey(ctx context.Context, key string) error
 }
 
 type brokerLogger interface {
 	PublishEvent(event model.GoodsEvent) error
+	Flush() error
+	IsConnected() bool
+	Status() string
 }
 
 type store interface {
 	CreateProject(ctx context.Context, project model.Project) (*model.Project, error)
+	GetProjectByID(ctx context.Context, id int64) (*model.Project, error)
+	GetProjects(ctx context.Context, params model.ProjectListParams) (*[]model.Project, error)
+	UpdateProject(ctx context.Context, request model.UpdateProjectRequest) (*model.Project, error)
+	DeleteProject(ctx context.Context, project model.Project) (*model.Project, *[]model.Goods, map[int64]model.Goods, error)
 
 	CreateGoods(ctx context.Context, goods model.Goods) (*model.Goods, error)
-	UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error)
-	DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, error)
-	GetGoods(ctx context.Context, params model.ListParams) (*[]model.Goods, error)
-	GetMetaData(ctx context.Context) (*model.ListParams, error)
-	ReprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, error)
+	BulkCreateGoods(ctx context.Context, projectID int64, goods []model.Goods) (*[]model.Goods, error)
+	BulkRemoveGoods(ctx context.Context, request model.BulkRemoveRequest) (*[]model.Goods, map[int64]model.Goods, error)
+	BulkUpdateGoods(ctx context.Context, request model.BulkUpdateRequest) (*[]model.Goods, map[int64]model.Goods, error)
+	UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, *[]model.Goods, map[int64]model.Goods, error)
+	DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, map[int64]model.Goods, error)
+	RestoreGoods(ctx context.Context, goods model.Goods) (*model.Goods, map[int64]model.Goods, error)
+	GetGoods(ctx context.Context, params model.ListParams) (*model.GetListResponse, error)
+	GetGoodByID(ctx context.Context, goods model.Goods) (*model.Goods, error)
+	ReprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, map[int64]model.Goods, error)
+	ReorderGoods(ctx context.Context, request model.ReorderRequest) (*[]model.Goods, map[int64]model.Goods, error)
+	RebalanceRanks(ctx context.Context, maxLength int) (int, error)
+	GetTrash(ctx context.Context, params model.ListParams) (*model.GetListResponse, error)
+	PurgeRemovedGoods(ctx context.Context, removedBefore time.Time, batchSize int) (*[]model.Goods, error)
+
+	AddOutboxEvents(ctx context.Context, events []model.GoodsEvent) error
+	GetPendingOutbox(ctx context.Context, limit int) (*[]model.OutboxMessage, error)
+	MarkOutboxSent(ctx context.Context, ids []int64) error
+	MarkOutboxFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time) error
+	DeleteSentOutbox(ctx context.Context, sentBefore time.Time, batchSize int) (int, error)
+
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
 }
 
 func New(db store, cash cashdb, bl brokerLogger) *Service {
 	return &Service{
-		db:   db,
-		cash: cash,
-		bl:   bl,
+		db:          db,
+		cash:        cash,
+		bl:          bl,
+		relayWakeup: make(chan struct{}, 1),
 	}
 }
 
+// BrokerStatus reports the state of the connection to the message broker.
+// Changes are kept in the outbox while it is not connected.
+func (s *Service) BrokerStatus() string {
+	return s.bl.Status()
+}
+
 func (s *Service) CreateProject(ctx context.Context, project model.Project) (*model.Project, error) {
+	if err := project.Validate().Err(); err != nil {
+		return nil, err
+	}
+
 	result, err := s.db.CreateProject(ctx, project)
 	if err != nil {
 		return nil, fmt.Errorf("s.db.CreateProject(ctx, project): %w", err)
@@ -55,66 +97,460 @@ func (s *Service) CreateProject(ctx context.Context, project model.Project) (*mo
 	return result, nil
 }
 
+func (s *Service) GetProject(ctx context.Context, project model.Project) (*model.Project, error) {
+	result, err := s.db.GetProjectByID(ctx, project.ID)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.GetProjectByID(ctx, project.ID): %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *Service) GetProjects(ctx context.Context, params model.ProjectListParams) (*[]model.Project, error) {
+	if err := params.Validate().Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.GetProjects(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.GetProjects(ctx, params): %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *Service) UpdateProject(ctx context.Context, request model.UpdateProjectRequest) (*model.Project, error) {
+	if err := request.Validate().Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.UpdateProject(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.UpdateProject(ctx, request): %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *Service) DeleteProject(ctx context.Context, project model.Project) (*model.Project, error) {
+	var result *model.Project
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var (
+			removedGoods *[]model.Goods
+			previous     map[int64]model.Goods
+			err          error
+		)
+
+		result, removedGoods, previous, err = s.db.DeleteProject(ctx, project)
+		if err != nil {
+			return fmt.Errorf("s.db.DeleteProject(ctx, project): %w", err)
+		}
+
+		return s.enqueueEvents(ctx, changeEvents(model.EventTypeRemoved, previous, *removedGoods))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("DeleteProject/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return result, nil
+}
+
+// checkProject reports an unknown project as a violation of the projectId
+// field next to the other violations, or as model.ErrProjectNotFound if the
+// request is otherwise valid.
+func (s *Service) checkProject(ctx context.Context, projectID int64, violations model.ValidationErrors) error {
+	if _, ok := violations["projectId"]; ok || projectID == 0 {
+		return nil
+	}
+
+	_, err := s.db.GetProjectByID(ctx, projectID)
+
+	switch {
+	case errors.Is(err, model.ErrProjectNotFound) && len(violations) == 0:
+		return fmt.Errorf("s.db.GetProjectByID(ctx, projectID): %w", err)
+	case errors.Is(err, model.ErrProjectNotFound):
+		violations.Add("projectId", model.CodeNotFound)
+	case err != nil:
+		return fmt.Errorf("s.db.GetProjectByID(ctx, projectID): %w", err)
+	}
+
+	return nil
+}
+
 func (s *Service) CreateGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
-	resultGood, err := s.db.CreateGoods(ctx, goods)
+	var result *model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		result, err = s.createGoods(ctx, goods)
+
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("s.db.CreateGoods(ctx, goods): %w", err)
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
 	}
 
+	s.wakeRelay()
+
 	if err = s.cash.InvalidateAllData(ctx); err != nil {
 		zap.L().With(zap.Error(err)).Warn("CreateGoods/s.cash.InvalidateAllData(ctx)")
 	}
 
-	return resultGood, nil
+	return result, nil
 }
 
-func (s *Service) UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error) {
-	if request.Name == "" {
-		return nil, model.ErrBlankName
+// createGoods validates the good and stores it with its event in the
+// transaction of ctx. The caller invalidates the cache after the commit.
+func (s *Service) createGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	violations := goods.Validate()
+	if err := s.checkProject(ctx, goods.ProjectID, violations); err != nil {
+		return nil, fmt.Errorf("s.checkProject(ctx, goods.ProjectID, violations): %w", err)
 	}
 
-	result, err := s.db.UpdateGoods(ctx, request)
+	if err := violations.Err(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.db.CreateGoods(ctx, goods)
 	if err != nil {
-		return nil, fmt.Errorf("s.db.UpdateGoods(ctx, request): %w", err)
+		return nil, fmt.Errorf("s.db.CreateGoods(ctx, goods): %w", err)
 	}
 
+	if err = s.enqueueEvents(ctx, createEvents([]model.Goods{*result})); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// BulkCreateGoods creates every valid good of the request in one transaction
+// and reports a result per requested item, invalid items included.
+func (s *Service) BulkCreateGoods(ctx context.Context, request model.BulkCreateRequest) (*[]model.BulkItemResult, error) {
+	results := make([]model.BulkItemResult, len(request.Goods))
+	validGoods := make([]model.Goods, 0, len(request.Goods))
+	validIndexes := make([]int, 0, len(request.Goods))
+
+	for i, goods := range request.Goods {
+		results[i].Index = i
+		goods.ProjectID = request.ProjectID
+
+		if violations := goods.Validate(); len(violations) > 0 {
+			results[i].Error = model.ErrValidation.Error()
+			results[i].Errors = violations
+
+			continue
+		}
+
+		validGoods = append(validGoods, goods)
+		validIndexes = append(validIndexes, i)
+	}
+
+	if len(validGoods) == 0 {
+		return &results, nil
+	}
+
+	var createdGoods *[]model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		createdGoods, err = s.db.BulkCreateGoods(ctx, request.ProjectID, validGoods)
+		if err != nil {
+			return fmt.Errorf("s.db.BulkCreateGoods(ctx, request.ProjectID, validGoods): %w", err)
+		}
+
+		return s.enqueueEvents(ctx, createEvents(*createdGoods))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
 	if err = s.cash.InvalidateAllData(ctx); err != nil {
-		zap.L().With(zap.Error(err)).Warn("UpdateGoods/s.cash.InvalidateAllData(ctx)")
+		zap.L().With(zap.Error(err)).Warn("BulkCreateGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	for i, goods := range *createdGoods {
+		results[validIndexes[i]].Goods = &goods
+	}
+
+	return &results, nil
+}
+
+// validateBulkSelection requires the goods of a bulk operation to be selected
+// either by ids or by a non-empty filter.
+func validateBulkSelection(ids []int64, filter *model.BulkFilter) error {
+	hasFilter := filter != nil && !filter.IsEmpty()
+	if (len(ids) > 0) == hasFilter {
+		return model.ErrWrongBulkFilter
+	}
+
+	return nil
+}
+
+func (s *Service) BulkRemoveGoods(ctx context.Context, request model.BulkRemoveRequest) (*[]model.Goods, error) {
+	if err := validateBulkSelection(request.IDs, request.Filter); err != nil {
+		return nil, err
+	}
+
+	var removedGoods *[]model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var (
+			previous map[int64]model.Goods
+			err      error
+		)
+
+		removedGoods, previous, err = s.db.BulkRemoveGoods(ctx, request)
+		if err != nil {
+			return fmt.Errorf("s.db.BulkRemoveGoods(ctx, request): %w", err)
+		}
+
+		if request.DryRun {
+			return nil
+		}
+
+		return s.enqueueEvents(ctx, changeEvents(model.EventTypeRemoved, previous, *removedGoods))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	if request.DryRun || len(*removedGoods) == 0 {
+		return removedGoods, nil
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("BulkRemoveGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return removedGoods, nil
+}
+
+func (s *Service) BulkUpdateGoods(ctx context.Context, request model.BulkUpdateRequest) (*[]model.Goods, error) {
+	if err := validateBulkSelection(request.IDs, request.Filter); err != nil {
+		return nil, err
+	}
+
+	if request.Set.Description == nil && request.Set.NamePattern == nil {
+		return nil, model.ErrEmptyBulkUpdate
+	}
+
+	if err := request.Validate().Err(); err != nil {
+		return nil, err
+	}
+
+	var updatedGoods *[]model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var (
+			previous map[int64]model.Goods
+			err      error
+		)
+
+		updatedGoods, previous, err = s.db.BulkUpdateGoods(ctx, request)
+		if err != nil {
+			return fmt.Errorf("s.db.BulkUpdateGoods(ctx, request): %w", err)
+		}
+
+		if err = request.ValidateNames(*updatedGoods).Err(); err != nil {
+			return err
+		}
+
+		if request.DryRun {
+			return nil
+		}
+
+		return s.enqueueEvents(ctx, changeEvents(model.EventTypeUpdated, previous, *updatedGoods))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	if request.DryRun || len(*updatedGoods) == 0 {
+		return updatedGoods, nil
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("BulkUpdateGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return updatedGoods, nil
+}
+
+// updatedGoods lists the updated good followed by the other goods moved by the
+// update.
+func updatedGoods(result *model.Goods, moved *[]model.Goods) []model.Goods {
+	changed := make([]model.Goods, 0, len(*moved)+1)
+	changed = append(changed, *result)
+
+	for _, value := range *moved {
+		if value.ID != result.ID {
+			changed = append(changed, value)
+		}
 	}
 
-	err = s.bl.PublishEvent(model.GoodsEvent{
-		Goods:     *result,
-		EventTime: time.Now(),
+	return changed
+}
+
+func (s *Service) UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error) {
+	var result *model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		result, err = s.updateGoods(ctx, request)
+
+		return err
 	})
 	if err != nil {
-		zap.L().With(zap.Error(err)).Warn("UpdateGoods/s.bl.PublishEvent(...)")
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("UpdateGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return result, nil
+}
+
+// updateGoods validates the patch and stores it with its events in the
+// transaction of ctx. The caller invalidates the cache after the commit.
+func (s *Service) updateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error) {
+	if err := request.Validate().Err(); err != nil {
+		return nil, err
+	}
+
+	result, moved, previous, err := s.db.UpdateGoods(ctx, request)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.UpdateGoods(ctx, request): %w", err)
+	}
+
+	if err = s.enqueueEvents(ctx, updateEvents(previous, result, moved)); err != nil {
+		return nil, err
 	}
 
 	return result, nil
 }
 
 func (s *Service) DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
-	result, err := s.db.DeleteGoods(ctx, goods)
+	var result *model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		result, err = s.deleteGoods(ctx, goods)
+
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("s.db.DeleteGoods(ctx, goods): %w", err)
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
 	}
 
+	s.wakeRelay()
+
 	if err = s.cash.InvalidateAllData(ctx); err != nil {
 		zap.L().With(zap.Error(err)).Warn("DeleteGoods/s.cash.InvalidateAllData(ctx)")
 	}
 
-	err = s.bl.PublishEvent(model.GoodsEvent{
-		Goods:     *result,
-		EventTime: time.Now(),
+	return result, nil
+}
+
+// deleteGoods removes the good and stores its event in the transaction of
+// ctx. The caller invalidates the cache after the commit.
+func (s *Service) deleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	result, previous, err := s.db.DeleteGoods(ctx, goods)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.DeleteGoods(ctx, goods): %w", err)
+	}
+
+	if err = s.enqueueEvents(ctx, changeEvents(model.EventTypeRemoved, previous, []model.Goods{*result})); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) RestoreGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	var result *model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var (
+			previous map[int64]model.Goods
+			err      error
+		)
+
+		result, previous, err = s.db.RestoreGoods(ctx, goods)
+		if err != nil {
+			return fmt.Errorf("s.db.RestoreGoods(ctx, goods): %w", err)
+		}
+
+		return s.enqueueEvents(ctx, changeEvents(model.EventTypeRestored, previous, []model.Goods{*result}))
 	})
 	if err != nil {
-		zap.L().With(zap.Error(err)).Warn("DeleteGoods/s.bl.PublishEvent(...)")
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("RestoreGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return result, nil
+}
+
+func (s *Service) GetGood(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	cashedGood, err := s.cash.GetGood(ctx, goods)
+
+	switch {
+	case err == nil:
+		return cashedGood, nil
+	case errors.Is(err, redis.Nil):
+		break
+	case err != nil:
+		zap.L().With(zap.Error(err)).Warn("GetGood/s.cash.GetGood(ctx, goods)")
+	}
+
+	result, err := s.db.GetGoodByID(ctx, goods)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.GetGoodByID(ctx, goods): %w", err)
+	}
+
+	if err = s.cash.StoreGood(ctx, *result); err != nil {
+		zap.L().With(zap.Error(err)).Warn("GetGood/s.cash.StoreGood(ctx, *result)")
 	}
 
 	return result, nil
 }
 
 func (s *Service) GetGoods(ctx context.Context, params model.ListParams) (*model.GetListResponse, error) {
+	params = params.WithDefaults()
+
+	violations := params.Validate()
+	if err := s.checkProject(ctx, params.ProjectID, violations); err != nil {
+		return nil, fmt.Errorf("s.checkProject(ctx, params.ProjectID, violations): %w", err)
+	}
+
+	if err := violations.Err(); err != nil {
+		return nil, err
+	}
+
 	cashedGoods, err := s.cash.GetListResponse(ctx, params)
 
 	switch {
@@ -126,54 +562,236 @@ func (s *Service) GetGoods(ctx context.Context, params model.ListParams) (*model
 		zap.L().With(zap.Error(err)).Warn("GetGoods/s.cash.GetListResponse(ctx, params)")
 	}
 
-	result, err := s.db.GetGoods(ctx, params)
+	listResponse, err := s.db.GetGoods(ctx, params)
 	if err != nil {
 		return nil, fmt.Errorf("s.db.GetGoods(ctx, params): %w", err)
 	}
 
-	metaData, err := s.db.GetMetaData(ctx)
-	if err != nil {
-		return nil, fmt.Errorf("s.db.GetMetaData(ctx): %w", err)
+	if listResponse.Meta.HasMore && params.IsPrioritySort() && len(listResponse.GoodsList) > 0 {
+		last := listResponse.GoodsList[len(listResponse.GoodsList)-1]
+		listResponse.Meta.NextCursor = model.Cursor{
+			ProjectID: last.ProjectID,
+			Priority:  last.Priority,
+			ID:        last.ID,
+		}.Encode()
 	}
 
-	metaData.Offset = params.Offset
-	metaData.Limit = params.Limit
+	if err = s.cash.StoreGetResponse(ctx, params, *listResponse); err != nil {
+		zap.L().With(zap.Error(err)).Warn("GetGoods/s.cash.StoreGetResponse(ctx, params, *listResponse)")
+	}
+
+	return listResponse, nil
+}
+
+func (s *Service) GetTrash(ctx context.Context, params model.ListParams) (*model.GetListResponse, error) {
+	params = params.WithDefaults()
+
+	violations := params.ValidateTrash()
+	if err := s.checkProject(ctx, params.ProjectID, violations); err != nil {
+		return nil, fmt.Errorf("s.checkProject(ctx, params.ProjectID, violations): %w", err)
+	}
 
-	listResponse := model.GetListResponse{
-		Meta:      *metaData,
-		GoodsList: *result,
+	if err := violations.Err(); err != nil {
+		return nil, err
 	}
 
-	if err = s.cash.StoreGetResponse(ctx, listResponse); err != nil {
-		zap.L().With(zap.Error(err)).Warn("GetGoods/s.cash.StoreGetResponse(ctx, listResponse)")
+	result, err := s.db.GetTrash(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("s.db.GetTrash(ctx, params): %w", err)
 	}
 
-	return &listResponse, nil
+	return result, nil
 }
 
 func (s *Service) ReprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, error) {
-	if goods.Priority < 1 {
-		return nil, model.ErrWrongPriority
+	var result *[]model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+
+		result, err = s.reprioritizeGoods(ctx, goods)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
 	}
 
-	result, err := s.db.ReprioritizeGoods(ctx, goods)
+	s.wakeRelay()
+
+	if err = s.cash.InvalidateAllData(ctx); err != nil {
+		zap.L().With(zap.Error(err)).Warn("ReprioritizeGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return result, nil
+}
+
+// reprioritizeGoods validates the move and stores it with its events in the
+// transaction of ctx. The caller invalidates the cache after the commit.
+func (s *Service) reprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, error) {
+	if err := goods.Validate().Err(); err != nil {
+		return nil, err
+	}
+
+	result, previous, err := s.db.ReprioritizeGoods(ctx, goods)
 	if err != nil {
 		return nil, fmt.Errorf("s.db.ReprioritizeGoods(ctx, goods): %w", err)
 	}
 
+	if err = s.enqueueEvents(ctx, changeEvents(model.EventTypeReprioritized, previous, *result)); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *Service) ReorderGoods(ctx context.Context, request model.ReorderRequest) (*[]model.Goods, error) {
+	violations := request.Validate()
+	if err := s.checkProject(ctx, request.ProjectID, violations); err != nil {
+		return nil, fmt.Errorf("s.checkProject(ctx, request.ProjectID, violations): %w", err)
+	}
+
+	if err := violations.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(request.IDs) == 0 {
+		return nil, model.ErrWrongReorder
+	}
+
+	seen := make(map[int64]bool, len(request.IDs))
+
+	for _, id := range request.IDs {
+		if seen[id] {
+			return nil, model.ErrWrongReorder
+		}
+
+		seen[id] = true
+	}
+
+	var result *[]model.Goods
+
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		var (
+			previous map[int64]model.Goods
+			err      error
+		)
+
+		result, previous, err = s.db.ReorderGoods(ctx, request)
+		if err != nil {
+			return fmt.Errorf("s.db.ReorderGoods(ctx, request): %w", err)
+		}
+
+		return s.enqueueEvents(ctx, changeEvents(model.EventTypeReprioritized, previous, *result))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s.db.WithTx(...): %w", err)
+	}
+
+	s.wakeRelay()
+
 	if err = s.cash.InvalidateAllData(ctx); err != nil {
-		zap.L().With(zap.Error(err)).Warn("ReprioritizeGoods/s.cash.InvalidateAllData(ctx)")
+		zap.L().With(zap.Error(err)).Warn("ReorderGoods/s.cash.InvalidateAllData(ctx)")
+	}
+
+	return result, nil
+}
+
+// RunRankRebalancer periodically respaces goods ranks that grew longer than
+// maxLength. It blocks until ctx is done.
+func (s *Service) RunRankRebalancer(ctx context.Context, interval time.Duration, maxLength int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rebalanced, err := s.db.RebalanceRanks(ctx, maxLength)
+			if err != nil {
+				zap.L().With(zap.Error(err)).Warn("RunRankRebalancer/s.db.RebalanceRanks(ctx, maxLength)")
+
+				continue
+			}
+
+			if rebalanced > 0 {
+				zap.L().Info("rebalanced goods ranks", zap.Int("projects", rebalanced))
+			}
+		}
+	}
+}
+
+// RunPurger periodically hard-deletes goods that were removed more than
+// retention ago and outbox messages sent more than outboxRetention ago,
+// batchSize rows at a time. It blocks until ctx is done.
+func (s *Service) RunPurger(ctx context.Context, interval, retention, outboxRetention time.Duration, batchSize int) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.purgeRemovedGoods(ctx, time.Now().Add(-retention), batchSize)
+			if err != nil {
+				zap.L().With(zap.Error(err)).Warn("RunPurger/s.purgeRemovedGoods(...)")
+			}
+
+			if purged > 0 {
+				zap.L().Info("purged removed goods", zap.Int("goods", purged))
+			}
+
+			pruned, err := s.pruneOutbox(ctx, time.Now().Add(-outboxRetention), batchSize)
+			if err != nil {
+				zap.L().With(zap.Error(err)).Warn("RunPurger/s.pruneOutbox(...)")
+			}
+
+			if pruned > 0 {
+				zap.L().Info("pruned sent outbox messages", zap.Int("messages", pruned))
+			}
+		}
 	}
+}
+
+func (s *Service) purgeRemovedGoods(ctx context.Context, removedBefore time.Time, batchSize int) (int, error) {
+	var purged int
+
+	defer func() {
+		if purged == 0 {
+			return
+		}
 
-	for _, value := range *result {
-		err = s.bl.PublishEvent(model.GoodsEvent{
-			Goods:     value,
-			EventTime: time.Now(),
+		if err := s.cash.InvalidateAllData(ctx); err != nil {
+			zap.L().With(zap.Error(err)).Warn("purgeRemovedGoods/s.cash.InvalidateAllData(ctx)")
+		}
+	}()
+
+	for ctx.Err() == nil {
+		var result *[]model.Goods
+
+		err := s.db.WithTx(ctx, func(ctx context.Context) error {
+			var err error
+
+			result, err = s.db.PurgeRemovedGoods(ctx, removedBefore, batchSize)
+			if err != nil {
+				return fmt.Errorf("s.db.PurgeRemovedGoods(ctx, removedBefore, batchSize): %w", err)
+			}
+
+			return s.enqueueEvents(ctx, purgeEvents(*result))
 		})
 		if err != nil {
-			zap.L().With(zap.Error(err)).Warn("ReprioritizeGoods/s.bl.PublishEvent(...)")
+			return purged, fmt.Errorf("s.db.WithTx(...): %w", err)
+		}
+
+		purged += len(*result)
+		s.wakeRelay()
+
+		if len(*result) < batchSize {
+			break
 		}
 	}
 
-	return result, nil
+	return purged, nil
 }
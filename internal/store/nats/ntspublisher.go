@@ -1,29 +1,130 @@
 package nats
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Saaghh/hezzl-hr/internal/config"
 	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"go.uber.org/zap"
 )
 
-const subject = "goods_logs"
+const (
+	subject = "goods_logs"
+
+	jetStreamTimeout = 5 * time.Second
+	flushTimeout     = 5 * time.Second
+)
 
 type Publisher struct {
-	conn *nats.Conn
+	conn   *nats.Conn
+	closed chan struct{}
+
+	// js is nil unless events are published to JetStream
+	js           jetstream.JetStream
+	streamConfig jetstream.StreamConfig
+	streamMu     sync.Mutex
+	streamReady  bool
 }
 
-func NewPublisher() (*Publisher, error) {
-	nc, err := nats.Connect(nats.DefaultURL)
+// NewPublisher connects to the servers of cfg. An unreachable server is not an
+// error: the connection keeps reconnecting in the background and publishing
+// fails until it succeeds, so the outbox keeps the events meanwhile.
+func NewPublisher(cfg *config.Config) (*Publisher, error) {
+	publisher := &Publisher{
+		closed: make(chan struct{}),
+	}
+
+	options, err := connectOptions(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connectOptions(cfg): %w", err)
+	}
+
+	options = append(options,
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			zap.L().With(zap.Error(err)).Warn("disconnected from nats")
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			zap.L().Info("reconnected to nats", zap.String("url", conn.ConnectedUrlRedacted()))
+		}),
+		nats.ClosedHandler(func(_ *nats.Conn) {
+			zap.L().Info("nats connection closed")
+			close(publisher.closed)
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			zap.L().With(zap.Error(err)).Warn("nats error")
+		}),
+	)
+
+	publisher.conn, err = nats.Connect(strings.Join(cfg.NatsURLs, ","), options...)
 	if err != nil {
-		return nil, fmt.Errorf("nats.Connect(nats.DefaultURL): %w", err)
+		return nil, fmt.Errorf("nats.Connect(...): %w", err)
+	}
+
+	if !cfg.NatsJetStream {
+		return publisher, nil
+	}
+
+	publisher.js, err = jetstream.New(publisher.conn)
+	if err != nil {
+		publisher.conn.Close()
+
+		return nil, fmt.Errorf("jetstream.New(publisher.conn): %w", err)
+	}
+
+	publisher.streamConfig = jetstream.StreamConfig{
+		Name:       cfg.NatsStream,
+		Subjects:   []string{subject},
+		Storage:    jetstream.FileStorage,
+		Duplicates: cfg.NatsDuplicateWindow,
 	}
 
-	return &Publisher{
-		conn: nc,
-	}, nil
+	return publisher, nil
+}
+
+func connectOptions(cfg *config.Config) ([]nats.Option, error) {
+	options := []nats.Option{
+		nats.Name("hezzl-apiserver"),
+		nats.RetryOnFailedConnect(true),
+		nats.MaxReconnects(cfg.NatsMaxReconnects),
+		nats.ReconnectWait(cfg.NatsReconnectWait),
+		// publishing fails while reconnecting instead of buffering messages that
+		// are lost if the connection never recovers
+		nats.ReconnectBufSize(-1),
+	}
+
+	switch {
+	case cfg.NatsCredsFile != "":
+		options = append(options, nats.UserCredentials(cfg.NatsCredsFile))
+	case cfg.NatsNKeyFile != "":
+		option, err := nats.NkeyOptionFromSeed(cfg.NatsNKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("nats.NkeyOptionFromSeed(cfg.NatsNKeyFile): %w", err)
+		}
+
+		options = append(options, option)
+	case cfg.NatsToken != "":
+		options = append(options, nats.Token(cfg.NatsToken))
+	case cfg.NatsUser != "":
+		options = append(options, nats.UserInfo(cfg.NatsUser, cfg.NatsPassword))
+	}
+
+	if cfg.NatsTLSCAFile != "" {
+		options = append(options, nats.RootCAs(cfg.NatsTLSCAFile))
+	}
+
+	if cfg.NatsTLSCertFile != "" {
+		options = append(options, nats.ClientCert(cfg.NatsTLSCertFile, cfg.NatsTLSKeyFile))
+	}
+
+	return options, nil
 }
 
 func (p *Publisher) PublishEvent(event model.GoodsEvent) error {
@@ -32,8 +133,11 @@ func (p *Publisher) PublishEvent(event model.GoodsEvent) error {
 		return fmt.Errorf("json.Marshal(event): %w", err)
 	}
 
-	err = p.conn.Publish(subject, eventString)
-	if err != nil {
+	if p.js != nil {
+		if err = p.publishToStream(event, eventString); err != nil {
+			return fmt.Errorf("p.publishToStream(event, eventString): %w", err)
+		}
+	} else if err = p.conn.Publish(subject, eventString); err != nil {
 		return fmt.Errorf("p.conn.Publish(subject, []byte(message)): %w", err)
 	}
 
@@ -41,3 +145,86 @@ func (p *Publisher) PublishEvent(event model.GoodsEvent) error {
 
 	return nil
 }
+
+// publishToStream waits for the stream to store the event. Events published
+// again, as the outbox does after a lost ack, are dropped by the stream.
+func (p *Publisher) publishToStream(event model.GoodsEvent, data []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), jetStreamTimeout)
+	defer cancel()
+
+	if err := p.ensureStream(ctx); err != nil {
+		return fmt.Errorf("p.ensureStream(ctx): %w", err)
+	}
+
+	options := make([]jetstream.PublishOpt, 0, 1)
+
+	// events of schema version 1 have no id to deduplicate by
+	if event.EventID != uuid.Nil {
+		options = append(options, jetstream.WithMsgID(event.EventID.String()))
+	}
+
+	ack, err := p.js.Publish(ctx, subject, data, options...)
+	if err != nil {
+		return fmt.Errorf("p.js.Publish(ctx, subject, data, options...): %w", err)
+	}
+
+	if ack.Duplicate {
+		zap.L().Debug("event already stored in stream", zap.String("eventId", event.EventID.String()))
+	}
+
+	return nil
+}
+
+// ensureStream creates or updates the stream on the first publish, so that
+// the publisher can start before NATS is reachable.
+func (p *Publisher) ensureStream(ctx context.Context) error {
+	p.streamMu.Lock()
+	defer p.streamMu.Unlock()
+
+	if p.streamReady {
+		return nil
+	}
+
+	if _, err := p.js.CreateOrUpdateStream(ctx, p.streamConfig); err != nil {
+		return fmt.Errorf("p.js.CreateOrUpdateStream(ctx, p.streamConfig): %w", err)
+	}
+
+	p.streamReady = true
+
+	return nil
+}
+
+// Flush waits until the server has received every event published to core
+// NATS. Events published to JetStream are acknowledged one by one already.
+func (p *Publisher) Flush() error {
+	if p.js != nil {
+		return nil
+	}
+
+	if err := p.conn.FlushTimeout(flushTimeout); err != nil {
+		return fmt.Errorf("p.conn.FlushTimeout(flushTimeout): %w", err)
+	}
+
+	return nil
+}
+
+// Status reports the state of the connection, such as CONNECTED or
+// RECONNECTING.
+func (p *Publisher) Status() string {
+	return p.conn.Status().String()
+}
+
+func (p *Publisher) IsConnected() bool {
+	return p.conn.IsConnected()
+}
+
+// Close flushes the published messages and closes the connection. It waits
+// for the drain timeout at most.
+func (p *Publisher) Close() {
+	if err := p.conn.Drain(); err != nil {
+		zap.L().With(zap.Error(err)).Warn("Close/p.conn.Drain()")
+		p.conn.Close()
+	}
+
+	<-p.closed
+}
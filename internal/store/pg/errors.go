@@ -0,0 +1,38 @@
+package pg
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes translated into domain errors.
+const (
+	foreignKeyViolation  = "23503"
+	uniqueViolation      = "23505"
+	exclusionViolation   = "23P01"
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// mapPgError translates constraint violations and transaction conflicts into
+// domain errors. The original error stays in the chain for logging, other
+// errors are returned as they are.
+func mapPgError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	switch pgErr.Code {
+	case foreignKeyViolation:
+		// goods.project_id is the only foreign key
+		return fmt.Errorf("%w: %w", model.ErrProjectNotFound, err)
+	case uniqueViolation, exclusionViolation, serializationFailure, deadlockDetected:
+		return fmt.Errorf("%w: %w", model.ErrConflict, err)
+	}
+
+	return err
+}
@@ -0,0 +1,138 @@
+package pg
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+var goodsSortOrders = map[string]string{
+	"":                     "project_id, priority, id",
+	model.SortPriority:     "project_id, priority, id",
+	model.SortPriorityDesc: "project_id DESC, priority DESC, id DESC",
+	model.SortCreatedAt:    "created_at, id",
+	model.SortName:         "name, id",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// goodsFilter collects the conditions of a goods listing. Conditions shared by
+// the page and its metadata go to conditions, conditions that only narrow the
+// page (removed flag, cursor) go to pageConditions.
+type goodsFilter struct {
+	conditions     []string
+	pageConditions []string
+	args           []any
+}
+
+func (f *goodsFilter) add(condition string, arg any) {
+	f.args = append(f.args, arg)
+	f.conditions = append(f.conditions, fmt.Sprintf(condition, len(f.args)))
+}
+
+func (f *goodsFilter) addCursor(cursor model.Cursor, descending bool) {
+	operator := ">"
+	if descending {
+		operator = "<"
+	}
+
+	f.args = append(f.args, cursor.ProjectID, cursor.Priority, cursor.ID)
+	f.pageConditions = append(f.pageConditions, fmt.Sprintf(
+		"(project_id, priority, id) %s ($%d, $%d, $%d)", operator, len(f.args)-2, len(f.args)-1, len(f.args)))
+}
+
+// nextArg reserves a placeholder for an argument that is appended by the caller.
+func (f *goodsFilter) nextArg(arg any) string {
+	f.args = append(f.args, arg)
+
+	return fmt.Sprintf("$%d", len(f.args))
+}
+
+func (f *goodsFilter) where() string {
+	return joinConditions(f.conditions)
+}
+
+func (f *goodsFilter) pageWhere() string {
+	return joinConditions(f.pageConditions)
+}
+
+func joinConditions(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+func newGoodsFilter(params model.ListParams) *goodsFilter {
+	filter := &goodsFilter{
+		conditions:     make([]string, 0),
+		pageConditions: make([]string, 0),
+		args:           make([]any, 0),
+	}
+
+	if !params.IncludeRemoved {
+		filter.pageConditions = append(filter.pageConditions, "removed = false")
+	}
+
+	if params.ProjectID != 0 {
+		filter.add("project_id = $%d", params.ProjectID)
+	}
+
+	if params.Name != "" {
+		pattern := likeEscaper.Replace(params.Name) + "%"
+		if params.NameMatch != model.NameMatchPrefix {
+			pattern = "%" + pattern
+		}
+
+		filter.add("name ILIKE $%d", pattern)
+	}
+
+	if params.PriorityFrom != 0 {
+		filter.add("priority >= $%d", params.PriorityFrom)
+	}
+
+	if params.PriorityTo != 0 {
+		filter.add("priority <= $%d", params.PriorityTo)
+	}
+
+	if params.CreatedFrom != nil {
+		filter.add("created_at >= $%d", *params.CreatedFrom)
+	}
+
+	if params.CreatedTo != nil {
+		filter.add("created_at <= $%d", *params.CreatedTo)
+	}
+
+	return filter
+}
+
+// newBulkFilter selects the active goods of a project either by ids or by the
+// bulk filter.
+func newBulkFilter(projectID int64, ids []int64, bulk *model.BulkFilter) *goodsFilter {
+	filter := &goodsFilter{
+		conditions: []string{"removed = false"},
+		args:       make([]any, 0),
+	}
+
+	filter.add("project_id = $%d", projectID)
+
+	if len(ids) > 0 {
+		filter.add("id = ANY($%d)", ids)
+	}
+
+	if bulk == nil {
+		return filter
+	}
+
+	if bulk.NamePrefix != "" {
+		filter.add("name ILIKE $%d", likeEscaper.Replace(bulk.NamePrefix)+"%")
+	}
+
+	if bulk.CreatedBefore != nil {
+		filter.add("created_at < $%d", *bulk.CreatedBefore)
+	}
+
+	return filter
+}
@@ -4,135 +4,284 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/Saaghh/hezzl-hr/internal/config"
+	"github.com/Saaghh/hezzl-hr/internal/lexorank"
 	"github.com/Saaghh/hezzl-hr/internal/model"
 	"github.com/jackc/pgx/v5"
 	"go.uber.org/zap"
 )
 
 func (p *Postgres) TruncateAllTables(ctx context.Context) error {
-	_, err := p.db.Exec(
+	_, err := p.conn(ctx).Exec(
 		ctx,
 		"TRUNCATE TABLE projects CASCADE")
 	if err != nil {
-		return fmt.Errorf("p.db.Exec(...): %w", err)
+		return fmt.Errorf("p.conn(ctx).Exec(...): %w", mapPgError(err))
 	}
 
-	_, err = p.db.Exec(
+	_, err = p.conn(ctx).Exec(
 		ctx,
 		"TRUNCATE TABLE goods CASCADE")
 	if err != nil {
-		return fmt.Errorf("p.db.Exec(...): %w", err)
+		return fmt.Errorf("p.conn(ctx).Exec(...): %w", mapPgError(err))
+	}
+
+	_, err = p.conn(ctx).Exec(
+		ctx,
+		"TRUNCATE TABLE outbox")
+	if err != nil {
+		return fmt.Errorf("p.conn(ctx).Exec(...): %w", mapPgError(err))
 	}
 
 	return nil
 }
 
-func (p *Postgres) CreateProject(ctx context.Context, project model.Project) (*model.Project, error) {
+// lastSlot returns the priority and rank that place a good at the end of the
+// project. The project must be locked by the caller.
+func lastSlot(ctx context.Context, tx pgx.Tx, projectID int64) (int, string, error) {
 	query := `
-	INSERT INTO projects (name) 
-	VALUES ($1)
-	RETURNING id, created_at`
+	SELECT COALESCE(MAX(priority), 0), COALESCE(MAX(rank), '')
+	FROM goods
+	WHERE project_id = $1 AND removed = false`
+
+	var (
+		maxPriority int
+		maxRank     string
+	)
 
-	err := p.db.QueryRow(
+	err := tx.QueryRow(
 		ctx,
 		query,
-		project.Name,
+		projectID,
 	).Scan(
-		&project.ID,
-		&project.CreatedAt,
+		&maxPriority,
+		&maxRank,
 	)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.QueryRow(...).Scan(...): %w", err)
+		return 0, "", fmt.Errorf("tx.QueryRow(...).Scan(&maxPriority, &maxRank): %w", mapPgError(err))
 	}
 
-	return &project, nil
+	rank, err := lexorank.Between(maxRank, "")
+	if err != nil {
+		return 0, "", fmt.Errorf("lexorank.Between(maxRank, \"\"): %w", err)
+	}
+
+	return maxPriority + 1, rank, nil
 }
 
-func (p *Postgres) CreateGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
-	query := `SELECT COALESCE(MAX(priority), 0) FROM goods`
+// lockProject serializes priority changes within a project: every transaction
+// that assigns or shifts priorities takes this lock first.
+func lockProject(ctx context.Context, tx pgx.Tx, projectID int64) error {
+	query := `
+	SELECT id FROM projects WHERE id = $1 AND removed = false FOR NO KEY UPDATE`
 
-	err := p.db.QueryRow(
+	err := tx.QueryRow(
 		ctx,
 		query,
-	).Scan(&goods.Priority)
+		projectID,
+	).Scan(&projectID)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return model.ErrProjectNotFound
+	case err != nil:
+		return fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+func (p *Postgres) CreateGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.QueryRow(...).Scan(&maxPriority): %w", err)
+		return nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
 	}
 
-	goods.Priority++
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("CreateGoods/tx.Rollback(ctx)")
+		}
+	}()
 
-	query = `
-	INSERT INTO goods (project_id, name, priority)
-	VALUES ($1, $2, $3)
-	RETURNING id, description, removed, created_at`
+	if err = lockProject(ctx, tx, goods.ProjectID); err != nil {
+		return nil, fmt.Errorf("lockProject(ctx, tx, goods.ProjectID): %w", err)
+	}
+
+	var rank string
+
+	goods.Priority, rank, err = lastSlot(ctx, tx, goods.ProjectID)
+	if err != nil {
+		return nil, fmt.Errorf("lastSlot(ctx, tx, goods.ProjectID): %w", err)
+	}
+
+	query := `
+	INSERT INTO goods (project_id, name, priority, rank)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id, description, removed, created_at, version`
 
-	err = p.db.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		goods.ProjectID,
 		goods.Name,
 		goods.Priority,
+		rank,
 	).Scan(
 		&goods.ID,
 		&goods.Description,
 		&goods.Removed,
 		&goods.CreatedAt,
+		&goods.Version,
 	)
-	// TODO: add foreign key violation check
 	if err != nil {
-		return nil, fmt.Errorf("p.db.QueryRow().Scan(): %w", err)
+		return nil, fmt.Errorf("tx.QueryRow().Scan(): %w", mapPgError(err))
+	}
+
+	if err = p.derivePriority(ctx, tx, &goods); err != nil {
+		return nil, fmt.Errorf("p.derivePriority(ctx, tx, &goods): %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
 	}
 
 	return &goods, nil
 }
 
-func (p *Postgres) UpdateGoods(ctx context.Context, request model.UpdateGoodsRequest) (*model.Goods, error) {
-	tx, err := p.db.Begin(ctx)
+// BulkCreateGoods inserts the goods at the end of the project in one
+// transaction and returns them in the same order with consecutive priorities.
+func (p *Postgres) BulkCreateGoods(ctx context.Context, projectID int64, goods []model.Goods) (*[]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.Begin(ctx): %w", err)
+		return nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
 	}
 
 	defer func() {
 		err := tx.Rollback(ctx)
 		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			zap.L().With(zap.Error(err)).Warn("UpdateWallet/tx.Rollback(ctx)")
+			zap.L().With(zap.Error(err)).Warn("BulkCreateGoods/tx.Rollback(ctx)")
 		}
 	}()
 
+	if err = lockProject(ctx, tx, projectID); err != nil {
+		return nil, fmt.Errorf("lockProject(ctx, tx, projectID): %w", err)
+	}
+
+	priority, rank, err := lastSlot(ctx, tx, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("lastSlot(ctx, tx, projectID): %w", err)
+	}
+
+	names := make([]string, 0, len(goods))
+	descriptions := make([]string, 0, len(goods))
+	priorities := make([]int, 0, len(goods))
+	ranks := make([]string, 0, len(goods))
+
+	for i, good := range goods {
+		if i > 0 {
+			if rank, err = lexorank.Between(rank, ""); err != nil {
+				return nil, fmt.Errorf("lexorank.Between(rank, \"\"): %w", err)
+			}
+		}
+
+		names = append(names, good.Name)
+		descriptions = append(descriptions, good.Description)
+		priorities = append(priorities, priority+i)
+		ranks = append(ranks, rank)
+	}
+
 	query := `
-	SELECT * FROM goods WHERE id = $1 AND project_id = $2 FOR UPDATE`
+	INSERT INTO goods (project_id, name, description, priority, rank)
+	SELECT $1, name, description, priority, rank
+	FROM unnest($2::varchar[], $3::varchar[], $4::int[], $5::varchar[]) AS g(name, description, priority, rank)
+	RETURNING id`
 
 	rows, err := tx.Query(
 		ctx,
 		query,
-		request.ID,
-		request.ProjectID,
-	)
-	rows.Close()
+		projectID,
+		names,
+		descriptions,
+		priorities,
+		ranks)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
 
-	switch {
-	case errors.Is(err, pgx.ErrNoRows):
-		return nil, model.ErrGoodNotFound
-	case err != nil:
-		return nil, fmt.Errorf("tx.QueryRow(ctx, query, request.ID, request.ProjectID).Scan(): %w", err)
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return nil, fmt.Errorf("pgx.CollectRows(rows, pgx.RowTo[int64]): %w", mapPgError(err))
 	}
 
-	query = `
+	// priorities are consecutive, so ordering by priority restores the input order
+	createdGoods, err := p.getGoodsByIDs(ctx, tx, projectID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("p.getGoodsByIDs(ctx, tx, projectID, ids): %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return createdGoods, nil
+}
+
+// UpdateGoods applies the patch in one transaction and returns the updated
+// good together with every good whose priority has changed by the move, and
+// the values all of them had before the update, keyed by id.
+func (p *Postgres) UpdateGoods(
+	ctx context.Context,
+	request model.UpdateGoodsRequest,
+) (*model.Goods, *[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("UpdateGoods/tx.Rollback(ctx)")
+		}
+	}()
+
+	if request.Priority != nil {
+		if err = lockProject(ctx, tx, request.ProjectID); err != nil {
+			return nil, nil, nil, fmt.Errorf("lockProject(ctx, tx, request.ProjectID): %w", err)
+		}
+	}
+
+	if err = lockGoods(ctx, tx, request.ID, request.ProjectID, request.Version); err != nil {
+		return nil, nil, nil, fmt.Errorf("lockGoods(ctx, tx, request.ID, request.ProjectID, request.Version): %w", err)
+	}
+
+	query := fmt.Sprintf(`
+	WITH %s
 	UPDATE goods
-	SET name = $1
-	WHERE removed = false and id = $2 and project_id = $3
-	RETURNING id, project_id, name, description, priority, removed, created_at`
+	SET name = COALESCE($3, name), description = COALESCE($4, description), version = version + 1
+	FROM previous
+	WHERE removed = false and id = $2 and project_id = $1 AND previous.previous_id = goods.id
+	RETURNING id, project_id, name, description, priority, removed, created_at, version,
+		%s`,
+		p.previousGoods(),
+		previousColumns)
 
-	var goods model.Goods
+	var (
+		goods    model.Goods
+		previous previousValues
+	)
 
 	err = tx.QueryRow(
 		ctx,
 		query,
-		request.Name,
-		request.ID,
 		request.ProjectID,
-	).Scan(
+		request.ID,
+		request.Name,
+		request.Description,
+	).Scan(append([]any{
 		&goods.ID,
 		&goods.ProjectID,
 		&goods.Name,
@@ -140,218 +289,796 @@ func (p *Postgres) UpdateGoods(ctx context.Context, request model.UpdateGoodsReq
 		&goods.Priority,
 		&goods.Removed,
 		&goods.CreatedAt,
-	)
+		&goods.Version,
+	}, previous.scanTargets()...)...)
 
 	switch {
 	case errors.Is(err, pgx.ErrNoRows):
-		return nil, model.ErrGoodNotFound
+		return nil, nil, nil, model.ErrGoodNotFound
 	case err != nil:
-		return nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", err)
-	}
-
-	if request.Description != nil {
-		query = `
-		UPDATE goods
-		SET description = $1
-		WHERE removed = false and id = $2 and project_id = $3
-		RETURNING description`
-
-		err = tx.QueryRow(
-			ctx,
-			query,
-			request.Description,
-			request.ID,
-			request.ProjectID,
-		).Scan(
-			&goods.Description,
-		)
+		return nil, nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	movedGoods := &[]model.Goods{}
+	previousGoods := make(map[int64]model.Goods)
+
+	if request.Priority != nil {
+		movedGoods, previousGoods, err = p.moveGoods(ctx, tx, model.UpdatePriorityRequest{
+			ID:        request.ID,
+			ProjectID: request.ProjectID,
+			Priority:  *request.Priority,
+		}, false)
 		if err != nil {
-			return nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", err)
+			return nil, nil, nil, fmt.Errorf("p.moveGoods(ctx, tx, ..., false): %w", err)
+		}
+
+		for _, moved := range *movedGoods {
+			if moved.ID == goods.ID {
+				goods.Priority = moved.Priority
+				goods.Version = moved.Version
+			}
 		}
 	}
 
+	// the move sees the good with the patch applied already
+	previousGoods[goods.ID] = previous.of(goods)
+
+	if err = p.derivePriority(ctx, tx, &goods); err != nil {
+		return nil, nil, nil, fmt.Errorf("p.derivePriority(ctx, tx, &goods): %w", err)
+	}
+
 	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("tx.Commit(ctx): %w", err)
+		return nil, nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
 	}
 
-	return &goods, nil
+	return &goods, movedGoods, previousGoods, nil
 }
 
-func (p *Postgres) DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+// lockGoods locks an active good and checks that it still has the expected
+// version. Zero expects any version.
+func lockGoods(ctx context.Context, tx pgx.Tx, id, projectID int64, version int) error {
 	query := `
-	UPDATE goods
-	SET removed = true
-	WHERE removed = false and id = $1 and project_id = $2
-	RETURNING removed`
+	SELECT version
+	FROM goods
+	WHERE id = $1 AND project_id = $2 AND removed = false
+	FOR UPDATE`
 
-	err := p.db.QueryRow(
+	var current int
+
+	err := tx.QueryRow(
 		ctx,
 		query,
-		goods.ID,
-		goods.ProjectID,
-	).Scan(
-		&goods.Removed,
-	)
+		id,
+		projectID,
+	).Scan(&current)
 
 	switch {
 	case errors.Is(err, pgx.ErrNoRows):
-		return nil, model.ErrGoodNotFound
+		return model.ErrGoodNotFound
 	case err != nil:
-		return nil, fmt.Errorf("p.db.QueryRow(...).Scan(): %w", err)
+		return fmt.Errorf("tx.QueryRow(...).Scan(&current): %w", mapPgError(err))
 	}
 
-	return &goods, nil
+	if version != 0 && version != current {
+		return model.ErrVersionMismatch
+	}
+
+	return nil
 }
 
-func (p *Postgres) GetMetaData(ctx context.Context) (*model.ListParams, error) {
-	var totalRecords model.ListParams
+// DeleteGoods removes an active good and returns it along with its values
+// before the removal, keyed by id.
+func (p *Postgres) DeleteGoods(ctx context.Context, goods model.Goods) (*model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
 
-	query := `SELECT COALESCE(COUNT(*), 0) FROM goods`
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("DeleteGoods/tx.Rollback(ctx)")
+		}
+	}()
 
-	err := p.db.QueryRow(
-		ctx,
-		query,
-	).Scan(
-		&totalRecords.Total,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("p.db.QueryRow(): %w", err)
+	if err = lockGoods(ctx, tx, goods.ID, goods.ProjectID, goods.Version); err != nil {
+		return nil, nil, fmt.Errorf("lockGoods(ctx, tx, goods.ID, goods.ProjectID, goods.Version): %w", err)
 	}
 
-	query += " WHERE removed = true"
+	query := fmt.Sprintf(`
+	WITH %s
+	UPDATE goods
+	SET removed = true, removed_at = now(), version = goods.version + 1
+	FROM previous
+	WHERE goods.removed = false AND goods.id = $2 AND goods.project_id = $1 AND previous.previous_id = goods.id
+	RETURNING goods.id, goods.project_id, goods.created_at, goods.removed, goods.removed_at, goods.version, %s`,
+		p.previousGoods(),
+		previousColumns)
+
+	var (
+		removed  model.Goods
+		previous previousValues
+	)
 
-	err = p.db.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
-	).Scan(
-		&totalRecords.Removed,
-	)
+		goods.ProjectID,
+		goods.ID,
+	).Scan(append([]any{
+		&removed.ID,
+		&removed.ProjectID,
+		&removed.CreatedAt,
+		&removed.Removed,
+		&removed.RemovedAt,
+		&removed.Version,
+	}, previous.scanTargets()...)...)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.QueryRow(): %w", err)
+		return nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
 	}
 
-	return &totalRecords, nil
+	before := previous.of(removed)
+
+	// the removed good keeps the values it had, its position included
+	removed.Name = before.Name
+	removed.Description = before.Description
+	removed.Priority = before.Priority
+
+	return &removed, map[int64]model.Goods{removed.ID: before}, nil
 }
 
-func (p *Postgres) GetGoods(ctx context.Context, params model.ListParams) (*[]model.Goods, error) {
-	goods := make([]model.Goods, 0, 1)
+// BulkRemoveGoods removes the active goods matching the request in one
+// transaction and returns them with their values before the removal, keyed by
+// id. A dry run rolls the transaction back and only reports the goods that
+// would be removed.
+func (p *Postgres) BulkRemoveGoods(
+	ctx context.Context,
+	request model.BulkRemoveRequest,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
 
-	query := `
-	SELECT id, project_id, name, description, priority, removed, created_at
-	FROM goods
-	WHERE removed = false
-	LIMIT $1 OFFSET $2`
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("BulkRemoveGoods/tx.Rollback(ctx)")
+		}
+	}()
 
-	rows, err := p.db.Query(
-		ctx,
-		query,
-		params.Limit,
-		params.Offset)
+	if err = lockProject(ctx, tx, request.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("lockProject(ctx, tx, request.ProjectID): %w", err)
+	}
+
+	filter := newBulkFilter(request.ProjectID, request.IDs, request.Filter)
+
+	// the filter takes $1 for the project, as previousGoods expects
+	query := fmt.Sprintf(`
+	WITH %s
+	UPDATE goods
+	SET removed = true, removed_at = now(), version = version + 1
+	FROM previous
+	%s AND previous.previous_id = goods.id
+	RETURNING id, project_id, name, description, priority, removed, created_at, version, removed_at,
+		%s`,
+		p.previousGoods(),
+		filter.where(),
+		previousColumns)
+
+	rows, err := tx.Query(ctx, query, filter.args...)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.Query(...): %w", err)
+		return nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
 	}
+	defer rows.Close()
+
+	removedGoods := make([]model.Goods, 0)
+	previousGoods := make(map[int64]model.Goods)
 
 	for rows.Next() {
-		var good model.Goods
+		var (
+			good     model.Goods
+			previous previousValues
+		)
 
-		err = rows.Scan(
+		err = rows.Scan(append([]any{
 			&good.ID,
 			&good.ProjectID,
 			&good.Name,
 			&good.Description,
 			&good.Priority,
 			&good.Removed,
-			&good.CreatedAt)
+			&good.CreatedAt,
+			&good.Version,
+			&good.RemovedAt,
+		}, previous.scanTargets()...)...)
 		if err != nil {
-			return nil, fmt.Errorf("rows.Scan(...): %w", err)
+			return nil, nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
 		}
 
-		goods = append(goods, good)
+		removedGoods = append(removedGoods, good)
+		previousGoods[good.ID] = previous.of(good)
 	}
 
-	return &goods, nil
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	if request.DryRun {
+		return &removedGoods, previousGoods, nil
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return &removedGoods, previousGoods, nil
 }
 
-func (p *Postgres) ReprioritizeGoods(ctx context.Context, goods model.UpdatePriorityRequest) (*[]model.Goods, error) {
-	tx, err := p.db.Begin(ctx)
+// BulkUpdateGoods applies the changes of the request to the matching active
+// goods in one transaction and returns them with their values before the
+// update, keyed by id. A dry run rolls the transaction back and reports the
+// goods as they would look after the update.
+func (p *Postgres) BulkUpdateGoods(
+	ctx context.Context,
+	request model.BulkUpdateRequest,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("BulkUpdateGoods/tx.Rollback(ctx)")
+		}
+	}()
+
+	if err = lockProject(ctx, tx, request.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("lockProject(ctx, tx, request.ProjectID): %w", err)
+	}
+
+	filter := newBulkFilter(request.ProjectID, request.IDs, request.Filter)
+
+	// {id} is substituted first so that a name containing "{id}" stays intact,
+	// the filter takes $1 for the project, as previousGoods expects
+	query := fmt.Sprintf(`
+	WITH %[4]s
+	UPDATE goods
+	SET description = COALESCE(%[1]s::varchar, description),
+		name = COALESCE(replace(replace(%[2]s::varchar, '{id}', id::text), '{name}', name), name),
+		version = version + 1
+	FROM previous
+	%[3]s AND previous.previous_id = goods.id
+	RETURNING id, %[5]s`,
+		filter.nextArg(request.Set.Description),
+		filter.nextArg(request.Set.NamePattern),
+		filter.where(),
+		p.previousGoods(),
+		previousColumns)
+
+	rows, err := tx.Query(ctx, query, filter.args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+
+	updatedGoods, previousGoods, err := p.collectChangedGoods(ctx, tx, request.ProjectID, rows)
 	if err != nil {
-		return nil, fmt.Errorf("p.db.Begin(ctx): %w", err)
+		return nil, nil, fmt.Errorf("p.collectChangedGoods(ctx, tx, request.ProjectID, rows): %w", err)
 	}
 
-	var changedGoods []model.Goods
+	if request.DryRun {
+		return updatedGoods, previousGoods, nil
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return updatedGoods, previousGoods, nil
+}
+
+// RestoreGoods brings a removed good back and places it at the end of its
+// project. It returns the good along with its values while it was removed,
+// keyed by id.
+func (p *Postgres) RestoreGoods(ctx context.Context, goods model.Goods) (*model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
 
 	defer func() {
 		err := tx.Rollback(ctx)
 		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
-			zap.L().With(zap.Error(err)).Warn("UpdateWallet/tx.Rollback(ctx)")
+			zap.L().With(zap.Error(err)).Warn("RestoreGoods/tx.Rollback(ctx)")
 		}
 	}()
 
+	if err = lockProject(ctx, tx, goods.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("lockProject(ctx, tx, goods.ProjectID): %w", err)
+	}
+
+	priority, rank, err := lastSlot(ctx, tx, goods.ProjectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("lastSlot(ctx, tx, goods.ProjectID): %w", err)
+	}
+
 	query := `
-	SELECT * FROM goods WHERE priority >= $1 FOR UPDATE`
+	WITH previous AS (
+		SELECT id, priority, removed_at, version
+		FROM goods
+		WHERE id = $3 AND project_id = $4
+	)
+	UPDATE goods
+	SET removed = false, removed_at = NULL, priority = $1, rank = $2, version = goods.version + 1
+	FROM previous
+	WHERE goods.removed = true AND goods.id = $3 AND goods.project_id = $4 AND previous.id = goods.id
+	RETURNING goods.id, goods.project_id, goods.name, goods.description, goods.priority, goods.removed,
+		goods.created_at, goods.version, previous.priority, previous.removed_at, previous.version`
 
-	rows, err := tx.Query(
+	var restored, previous model.Goods
+
+	err = tx.QueryRow(
 		ctx,
 		query,
-		goods.Priority,
+		priority,
+		rank,
+		goods.ID,
+		goods.ProjectID,
+	).Scan(
+		&restored.ID,
+		&restored.ProjectID,
+		&restored.Name,
+		&restored.Description,
+		&restored.Priority,
+		&restored.Removed,
+		&restored.CreatedAt,
+		&restored.Version,
+		&previous.Priority,
+		&previous.RemovedAt,
+		&previous.Version,
 	)
-	rows.Close()
 
-	if err != nil {
-		return nil, fmt.Errorf("tx.Query(...): %w", err)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil, model.ErrGoodNotFound
+	case err != nil:
+		return nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
 	}
 
-	query = `
-	UPDATE goods
-	SET priority = $1
-	WHERE id = $2 and project_id = $3 and removed = false
-	RETURNING priority`
+	if err = p.derivePriority(ctx, tx, &restored); err != nil {
+		return nil, nil, fmt.Errorf("p.derivePriority(ctx, tx, &restored): %w", err)
+	}
 
-	err = tx.QueryRow(
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	previous.ID = restored.ID
+	previous.ProjectID = restored.ProjectID
+	previous.Name = restored.Name
+	previous.Description = restored.Description
+	previous.CreatedAt = restored.CreatedAt
+	previous.Removed = true
+
+	return &restored, map[int64]model.Goods{restored.ID: previous}, nil
+}
+
+func (p *Postgres) GetGoodByID(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	query := `
+	SELECT id, project_id, name, description, priority, removed, created_at, version
+	FROM %s
+	WHERE goods.id = $1 AND goods.project_id = $2 AND goods.removed = false`
+
+	var good model.Goods
+
+	err := p.conn(ctx).QueryRow(
 		ctx,
-		query,
-		goods.Priority,
+		fmt.Sprintf(query, p.goodsSource()),
 		goods.ID,
 		goods.ProjectID,
 	).Scan(
-		&goods.Priority,
+		&good.ID,
+		&good.ProjectID,
+		&good.Name,
+		&good.Description,
+		&good.Priority,
+		&good.Removed,
+		&good.CreatedAt,
+		&good.Version,
 	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, model.ErrGoodNotFound
+	case err != nil:
+		return nil, fmt.Errorf("p.conn(ctx).QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	return &good, nil
+}
+
+// GetGoods returns a page of goods matching params together with the list
+// metadata computed for the same filter. A cursor, when given, takes
+// precedence over the offset.
+func (p *Postgres) GetGoods(ctx context.Context, params model.ListParams) (*model.GetListResponse, error) {
+	goods := make([]model.Goods, 0, params.Limit+1)
+
+	filter := newGoodsFilter(params)
+	offset := params.Offset
+
+	if params.Cursor != "" {
+		cursor, err := model.DecodeCursor(params.Cursor)
+		if err != nil {
+			return nil, fmt.Errorf("model.DecodeCursor(params.Cursor): %w", err)
+		}
+
+		filter.addCursor(*cursor, params.Sort == model.SortPriorityDesc)
+		offset = 0
+	}
+
+	query := fmt.Sprintf(`
+	WITH matched AS (
+		SELECT id, project_id, name, description, priority, removed, created_at, version
+		FROM %s
+		%s
+	), meta AS (
+		SELECT
+			COUNT(*) FILTER (WHERE removed = false) AS active_count,
+			COUNT(*) FILTER (WHERE removed = true) AS removed_count
+		FROM matched
+	), page AS (
+		SELECT *
+		FROM matched
+		%s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	)
+	SELECT meta.active_count, meta.removed_count,
+		page.id, page.project_id, page.name, page.description, page.priority, page.removed, page.created_at, page.version
+	FROM meta
+	LEFT JOIN page ON true
+	ORDER BY %s`,
+		p.goodsSource(),
+		filter.where(),
+		filter.pageWhere(),
+		goodsSortOrders[params.Sort],
+		filter.nextArg(params.Limit+1),
+		filter.nextArg(offset),
+		goodsSortOrders[params.Sort])
+
+	rows, err := p.conn(ctx).Query(
+		ctx,
+		query,
+		filter.args...)
 	if err != nil {
-		return nil, fmt.Errorf("tx.QueryRow(...): %w", err)
+		return nil, fmt.Errorf("p.conn(ctx).Query(...): %w", mapPgError(err))
 	}
+	defer rows.Close()
 
-	changedGoods = append(changedGoods, model.Goods{ID: goods.ID, Priority: goods.Priority})
+	meta := params
 
-	query = `
+	for rows.Next() {
+		var (
+			good        model.Goods
+			id          *int64
+			projectID   *int64
+			name        *string
+			description *string
+			priority    *int
+			removed     *bool
+			version     *int
+		)
+
+		err = rows.Scan(
+			&meta.Active,
+			&meta.Removed,
+			&id,
+			&projectID,
+			&name,
+			&description,
+			&priority,
+			&removed,
+			&good.CreatedAt,
+			&version)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		// the page is empty, only metadata was returned
+		if id == nil {
+			continue
+		}
+
+		good.ID = *id
+		good.ProjectID = *projectID
+		good.Name = *name
+		good.Description = *description
+		good.Priority = *priority
+		good.Removed = *removed
+		good.Version = *version
+
+		goods = append(goods, good)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	meta.Total = meta.Active
+	if params.IncludeRemoved {
+		meta.Total += meta.Removed
+	}
+
+	meta.HasMore = len(goods) > params.Limit
+	if meta.HasMore {
+		goods = goods[:params.Limit]
+	}
+
+	return &model.GetListResponse{
+		Meta:      meta,
+		GoodsList: goods,
+	}, nil
+}
+
+// ReprioritizeGoods moves the good to the requested position within its
+// project, shifting the goods in between by one, and returns every good whose
+// priority has changed with their values before the move, keyed by id.
+// Priorities of the project stay dense (1..N).
+func (p *Postgres) ReprioritizeGoods(
+	ctx context.Context,
+	goods model.UpdatePriorityRequest,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("ReprioritizeGoods/tx.Rollback(ctx)")
+		}
+	}()
+
+	if err = lockProject(ctx, tx, goods.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("lockProject(ctx, tx, goods.ProjectID): %w", err)
+	}
+
+	if err = lockGoods(ctx, tx, goods.ID, goods.ProjectID, goods.Version); err != nil {
+		return nil, nil, fmt.Errorf("lockGoods(ctx, tx, goods.ID, goods.ProjectID, goods.Version): %w", err)
+	}
+
+	changedGoods, previousGoods, err := p.moveGoods(ctx, tx, goods, true)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.moveGoods(ctx, tx, goods, true): %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return changedGoods, previousGoods, nil
+}
+
+// ReorderGoods places the given goods in the requested order on the positions
+// they currently occupy and returns every good whose priority has changed with
+// their values before the reorder, keyed by id.
+func (p *Postgres) ReorderGoods(
+	ctx context.Context,
+	request model.ReorderRequest,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("ReorderGoods/tx.Rollback(ctx)")
+		}
+	}()
+
+	if err = lockProject(ctx, tx, request.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("lockProject(ctx, tx, request.ProjectID): %w", err)
+	}
+
+	query := `
+	SELECT COUNT(*)
+	FROM goods
+	WHERE project_id = $1 AND removed = false AND id = ANY($2)`
+
+	var found int
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		request.ProjectID,
+		request.IDs,
+	).Scan(&found)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(&found): %w", mapPgError(err))
+	}
+
+	if found != len(request.IDs) {
+		return nil, nil, model.ErrGoodNotFound
+	}
+
+	setPriority := ", priority = slots.priority"
+	if p.ranking == config.RankingLexorank {
+		setPriority = ""
+	}
+
+	query = fmt.Sprintf(`
+	WITH %[3]s, requested AS (
+		SELECT id, ordinality
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS r(id, ordinality)
+	), slots AS (
+		SELECT priority, rank, ROW_NUMBER() OVER (ORDER BY %[1]s, id) AS ordinality
+		FROM goods
+		WHERE project_id = $1 AND removed = false AND id = ANY($2)
+	)
 	UPDATE goods
-	SET priority = priority + 1
-	WHERE priority > $1 and removed = false
-	RETURNING id, priority`
+	SET rank = slots.rank%[2]s, version = goods.version + 1
+	FROM requested
+	JOIN slots USING (ordinality)
+	JOIN previous ON previous.previous_id = requested.id
+	WHERE goods.id = requested.id AND goods.project_id = $1 AND goods.%[1]s <> slots.%[1]s
+	RETURNING goods.id, %[4]s`,
+		p.orderColumn(),
+		setPriority,
+		p.previousGoods(),
+		previousColumns)
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		request.ProjectID,
+		request.IDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+
+	changedGoods, previousGoods, err := p.collectChangedGoods(ctx, tx, request.ProjectID, rows)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.collectChangedGoods(ctx, tx, request.ProjectID, rows): %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return changedGoods, previousGoods, nil
+}
+
+// GetTrash returns a page of removed goods, most recently removed first.
+func (p *Postgres) GetTrash(ctx context.Context, params model.ListParams) (*model.GetListResponse, error) {
+	meta := params
+
+	// counted apart from the page, which is empty past the end of the trash
+	query := `
+	SELECT COUNT(*)
+	FROM goods
+	WHERE removed = true AND project_id = $1`
+
+	err := p.conn(ctx).QueryRow(
+		ctx,
+		query,
+		params.ProjectID,
+	).Scan(&meta.Removed)
+	if err != nil {
+		return nil, fmt.Errorf("p.conn(ctx).QueryRow(...).Scan(&meta.Removed): %w", mapPgError(err))
+	}
 
-	rows, err = tx.Query(
+	query = `
+	SELECT id, project_id, name, description, priority, removed, created_at, version, removed_at
+	FROM goods
+	WHERE removed = true AND project_id = $1
+	ORDER BY removed_at DESC NULLS LAST, id DESC
+	LIMIT $2 OFFSET $3`
+
+	rows, err := p.conn(ctx).Query(
 		ctx,
 		query,
-		goods.Priority)
+		params.ProjectID,
+		params.Limit,
+		params.Offset)
 	if err != nil {
-		return nil, fmt.Errorf("tx.Query(...): %w", err)
+		return nil, fmt.Errorf("p.conn(ctx).Query(...): %w", mapPgError(err))
 	}
+	defer rows.Close()
+
+	goods := make([]model.Goods, 0, params.Limit)
 
 	for rows.Next() {
 		var good model.Goods
 
 		err = rows.Scan(
 			&good.ID,
-			&good.Priority)
+			&good.ProjectID,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.CreatedAt,
+			&good.Version,
+			&good.RemovedAt)
 		if err != nil {
-			return nil, fmt.Errorf("rows.Scan(...): %w", err)
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
 		}
 
-		changedGoods = append(changedGoods, good)
+		goods = append(goods, good)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
 	}
 
-	if err = tx.Commit(ctx); err != nil {
-		return nil, fmt.Errorf("tx.Commit(ctx): %w", err)
+	meta.Total = meta.Removed
+	meta.HasMore = params.Offset+len(goods) < meta.Total
+
+	return &model.GetListResponse{
+		Meta:      meta,
+		GoodsList: goods,
+	}, nil
+}
+
+// PurgeRemovedGoods hard-deletes at most batchSize goods removed before
+// removedBefore and returns them.
+func (p *Postgres) PurgeRemovedGoods(ctx context.Context, removedBefore time.Time, batchSize int) (*[]model.Goods, error) {
+	query := `
+	DELETE FROM goods
+	WHERE (id, project_id) IN (
+		SELECT id, project_id
+		FROM goods
+		WHERE removed = true AND removed_at < $1
+		ORDER BY removed_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	)
+	RETURNING id, project_id, name, description, priority, removed, created_at, version, removed_at`
+
+	rows, err := p.conn(ctx).Query(
+		ctx,
+		query,
+		removedBefore,
+		batchSize)
+	if err != nil {
+		return nil, fmt.Errorf("p.conn(ctx).Query(...): %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	purgedGoods := make([]model.Goods, 0, batchSize)
+
+	for rows.Next() {
+		var good model.Goods
+
+		err = rows.Scan(
+			&good.ID,
+			&good.ProjectID,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.CreatedAt,
+			&good.Version,
+			&good.RemovedAt)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		purgedGoods = append(purgedGoods, good)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
 	}
 
-	return &changedGoods, nil
+	return &purgedGoods, nil
 }
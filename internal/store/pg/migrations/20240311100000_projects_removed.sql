@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE projects ADD COLUMN removed boolean not null default false;
+
+CREATE INDEX idx_goods_project_id ON goods (project_id);
+
+-- +migrate Down
+
+DROP INDEX idx_goods_project_id;
+
+ALTER TABLE projects DROP COLUMN removed;
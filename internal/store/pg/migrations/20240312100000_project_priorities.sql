@@ -0,0 +1,16 @@
+-- +migrate Up
+
+UPDATE goods
+SET priority = ranked.position
+FROM (
+    SELECT id, project_id, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY priority, id) AS position
+    FROM goods
+    WHERE removed = false
+) AS ranked
+WHERE goods.id = ranked.id AND goods.project_id = ranked.project_id;
+
+CREATE INDEX idx_goods_project_priority ON goods (project_id, priority);
+
+-- +migrate Down
+
+DROP INDEX idx_goods_project_priority;
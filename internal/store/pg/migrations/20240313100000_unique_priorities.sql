@@ -0,0 +1,10 @@
+-- +migrate Up
+
+-- deferred so that statements shifting a range of priorities are checked only at commit
+ALTER TABLE goods ADD CONSTRAINT goods_project_priority_unique
+    EXCLUDE USING btree (project_id WITH =, priority WITH =) WHERE (removed = false)
+    DEFERRABLE INITIALLY DEFERRED;
+
+-- +migrate Down
+
+ALTER TABLE goods DROP CONSTRAINT goods_project_priority_unique;
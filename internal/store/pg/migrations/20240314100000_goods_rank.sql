@@ -0,0 +1,22 @@
+-- +migrate Up
+
+ALTER TABLE goods ADD COLUMN rank varchar COLLATE "C" not null default '';
+
+UPDATE goods
+SET rank = lpad(ranked.position::text, 10, '0')
+FROM (
+    SELECT id, project_id, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY removed, priority, id) AS position
+    FROM goods
+) AS ranked
+WHERE goods.id = ranked.id AND goods.project_id = ranked.project_id;
+
+-- deferred for the same reason as goods_project_priority_unique: reorders permute ranks
+ALTER TABLE goods ADD CONSTRAINT goods_project_rank_unique
+    EXCLUDE USING btree (project_id WITH =, rank WITH =) WHERE (removed = false)
+    DEFERRABLE INITIALLY DEFERRED;
+
+-- +migrate Down
+
+ALTER TABLE goods DROP CONSTRAINT goods_project_rank_unique;
+
+ALTER TABLE goods DROP COLUMN rank;
@@ -0,0 +1,13 @@
+-- +migrate Up
+
+ALTER TABLE goods ADD COLUMN removed_at timestamp with time zone;
+
+UPDATE goods SET removed_at = now() WHERE removed = true;
+
+CREATE INDEX idx_goods_removed_at ON goods (removed_at) WHERE removed = true;
+
+-- +migrate Down
+
+DROP INDEX idx_goods_removed_at;
+
+ALTER TABLE goods DROP COLUMN removed_at;
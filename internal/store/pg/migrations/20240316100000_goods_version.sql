@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE goods ADD COLUMN version integer NOT NULL DEFAULT 1;
+
+-- +migrate Down
+
+ALTER TABLE goods DROP COLUMN version;
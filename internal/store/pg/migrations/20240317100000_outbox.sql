@@ -0,0 +1,17 @@
+-- +migrate Up
+
+CREATE TABLE outbox (
+    id bigserial not null primary key,
+    payload jsonb not null,
+    created_at timestamp with time zone not null default now(),
+    attempts integer not null default 0,
+    last_error text,
+    next_attempt_at timestamp with time zone not null default now(),
+    sent_at timestamp with time zone
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (next_attempt_at, id) WHERE sent_at IS NULL;
+
+-- +migrate Down
+
+DROP TABLE outbox;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+CREATE INDEX idx_outbox_sent ON outbox (sent_at) WHERE sent_at IS NOT NULL;
+
+-- +migrate Down
+
+DROP INDEX idx_outbox_sent;
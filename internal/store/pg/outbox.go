@@ -0,0 +1,135 @@
+package pg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+// AddOutboxEvents stores events for the relay. Called with a context of
+// WithTx, the events are committed or rolled back with the change that caused
+// them.
+func (p *Postgres) AddOutboxEvents(ctx context.Context, events []model.GoodsEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	payloads := make([]string, 0, len(events))
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("json.Marshal(event): %w", err)
+		}
+
+		payloads = append(payloads, string(payload))
+	}
+
+	query := `
+	INSERT INTO outbox (payload)
+	SELECT payload FROM unnest($1::jsonb[]) WITH ORDINALITY AS t(payload, ord)
+	ORDER BY ord`
+
+	if _, err := p.conn(ctx).Exec(ctx, query, payloads); err != nil {
+		return fmt.Errorf("p.conn(ctx).Exec(ctx, query, payloads): %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// GetPendingOutbox locks at most limit unsent messages that are due, oldest
+// first. Messages locked by another relay are skipped. The locks last until
+// the end of the transaction, so it is meant to be called within WithTx.
+func (p *Postgres) GetPendingOutbox(ctx context.Context, limit int) (*[]model.OutboxMessage, error) {
+	query := `
+	SELECT id, payload, attempts
+	FROM outbox
+	WHERE sent_at IS NULL AND next_attempt_at <= now()
+	ORDER BY id
+	LIMIT $1
+	FOR UPDATE SKIP LOCKED`
+
+	rows, err := p.conn(ctx).Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("p.conn(ctx).Query(ctx, query, limit): %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	messages := make([]model.OutboxMessage, 0, limit)
+
+	for rows.Next() {
+		var (
+			message model.OutboxMessage
+			payload []byte
+		)
+
+		if err = rows.Scan(&message.ID, &payload, &message.Attempts); err != nil {
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		if err = json.Unmarshal(payload, &message.Event); err != nil {
+			return nil, fmt.Errorf("json.Unmarshal(payload, &message.Event): %w", err)
+		}
+
+		messages = append(messages, message)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	return &messages, nil
+}
+
+func (p *Postgres) MarkOutboxSent(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	query := `UPDATE outbox SET sent_at = now() WHERE id = ANY($1)`
+
+	if _, err := p.conn(ctx).Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("p.conn(ctx).Exec(ctx, query, ids): %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// DeleteSentOutbox deletes at most batchSize messages sent before sentBefore
+// and returns how many were deleted.
+func (p *Postgres) DeleteSentOutbox(ctx context.Context, sentBefore time.Time, batchSize int) (int, error) {
+	query := `
+	DELETE FROM outbox
+	WHERE id IN (
+		SELECT id
+		FROM outbox
+		WHERE sent_at < $1
+		ORDER BY sent_at
+		LIMIT $2
+	)`
+
+	tag, err := p.conn(ctx).Exec(ctx, query, sentBefore, batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("p.conn(ctx).Exec(ctx, query, sentBefore, batchSize): %w", mapPgError(err))
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// MarkOutboxFailed records a failed publish and postpones the next attempt
+// until nextAttempt.
+func (p *Postgres) MarkOutboxFailed(ctx context.Context, id int64, lastError string, nextAttempt time.Time) error {
+	query := `
+	UPDATE outbox
+	SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+	WHERE id = $1`
+
+	if _, err := p.conn(ctx).Exec(ctx, query, id, lastError, nextAttempt); err != nil {
+		return fmt.Errorf("p.conn(ctx).Exec(ctx, query, id, lastError, nextAttempt): %w", mapPgError(err))
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"net/url"
 
@@ -14,15 +15,22 @@ import (
 	"go.uber.org/zap"
 )
 
+var ErrUnknownRankingMode = errors.New("unknown ranking mode")
+
 type Postgres struct {
-	db  *pgxpool.Pool
-	dsn string
+	db      *pgxpool.Pool
+	dsn     string
+	ranking string
 }
 
 //go:embed migrations
 var migrations embed.FS
 
 func New(ctx context.Context, cfg *config.Config) (*Postgres, error) {
+	if cfg.RankingMode != config.RankingInteger && cfg.RankingMode != config.RankingLexorank {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRankingMode, cfg.RankingMode)
+	}
+
 	urlScheme := url.URL{
 		Scheme:   "postgres",
 		User:     url.UserPassword(cfg.PGUser, cfg.PGPassword),
@@ -46,8 +54,9 @@ func New(ctx context.Context, cfg *config.Config) (*Postgres, error) {
 	zap.L().Info("successfully connected to postgres")
 
 	return &Postgres{
-		db:  db,
-		dsn: dsn,
+		db:      db,
+		dsn:     dsn,
+		ranking: cfg.RankingMode,
 	}, nil
 }
 
@@ -0,0 +1,102 @@
+package pg
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// previousColumns are the columns of previousGoods returned by a statement
+// along with the goods it changes.
+const previousColumns = `previous.previous_name, previous.previous_description,
+	previous.previous_priority, previous.previous_version`
+
+// previousGoods is a CTE of the goods of the project $1 as they are before the
+// statement it belongs to changes them. Statements join it on previous_id to
+// return the previous values of the goods they change, so no goods are read
+// ahead of the change.
+func (p *Postgres) previousGoods() string {
+	return fmt.Sprintf(`previous AS (
+		SELECT goods.id AS previous_id, goods.name AS previous_name, goods.description AS previous_description,
+			goods.priority AS previous_priority, goods.version AS previous_version
+		FROM %s
+		WHERE goods.project_id = $1
+	)`,
+		p.goodsSource())
+}
+
+// previousValues are the values of an active good read through previousGoods.
+type previousValues struct {
+	name        string
+	description string
+	priority    int
+	version     int
+}
+
+func (v *previousValues) scanTargets() []any {
+	return []any{&v.name, &v.description, &v.priority, &v.version}
+}
+
+// of returns the good as it was before the change.
+func (v *previousValues) of(current model.Goods) model.Goods {
+	previous := current
+	previous.Name = v.name
+	previous.Description = v.description
+	previous.Priority = v.priority
+	previous.Version = v.version
+	previous.Removed = false
+	previous.RemovedAt = nil
+
+	return previous
+}
+
+// collectChangedGoods reads the changed goods of the project whose ids are
+// returned by rows followed by previousColumns and returns them with their
+// previous values, keyed by id.
+func (p *Postgres) collectChangedGoods(
+	ctx context.Context,
+	tx pgx.Tx,
+	projectID int64,
+	rows pgx.Rows,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	ids := make([]int64, 0)
+	previousByID := make(map[int64]previousValues)
+
+	for rows.Next() {
+		var (
+			id       int64
+			previous previousValues
+		)
+
+		if err := rows.Scan(append([]any{&id}, previous.scanTargets()...)...); err != nil {
+			rows.Close()
+
+			return nil, nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		ids = append(ids, id)
+		previousByID[id] = previous
+	}
+
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	changedGoods, err := p.getGoodsByIDs(ctx, tx, projectID, ids)
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.getGoodsByIDs(ctx, tx, projectID, ids): %w", err)
+	}
+
+	previousGoods := make(map[int64]model.Goods, len(*changedGoods))
+
+	for _, changed := range *changedGoods {
+		previous := previousByID[changed.ID]
+		previousGoods[changed.ID] = previous.of(changed)
+	}
+
+	return changedGoods, previousGoods, nil
+}
@@ -0,0 +1,239 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+func (p *Postgres) CreateProject(ctx context.Context, project model.Project) (*model.Project, error) {
+	query := `
+	INSERT INTO projects (name) 
+	VALUES ($1)
+	RETURNING id, removed, created_at`
+
+	err := p.conn(ctx).QueryRow(
+		ctx,
+		query,
+		project.Name,
+	).Scan(
+		&project.ID,
+		&project.Removed,
+		&project.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("p.conn(ctx).QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	return &project, nil
+}
+
+func (p *Postgres) GetProjectByID(ctx context.Context, id int64) (*model.Project, error) {
+	query := `
+	SELECT id, name, removed, created_at
+	FROM projects
+	WHERE id = $1 AND removed = false`
+
+	var project model.Project
+
+	err := p.conn(ctx).QueryRow(
+		ctx,
+		query,
+		id,
+	).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Removed,
+		&project.CreatedAt,
+	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, model.ErrProjectNotFound
+	case err != nil:
+		return nil, fmt.Errorf("p.conn(ctx).QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	return &project, nil
+}
+
+func (p *Postgres) GetProjects(ctx context.Context, params model.ProjectListParams) (*[]model.Project, error) {
+	projects := make([]model.Project, 0, params.Limit)
+
+	query := `
+	SELECT id, name, removed, created_at
+	FROM projects
+	WHERE removed = false
+	ORDER BY id
+	LIMIT $1 OFFSET $2`
+
+	rows, err := p.conn(ctx).Query(
+		ctx,
+		query,
+		params.Limit,
+		params.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("p.conn(ctx).Query(...): %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var project model.Project
+
+		err = rows.Scan(
+			&project.ID,
+			&project.Name,
+			&project.Removed,
+			&project.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	return &projects, nil
+}
+
+func (p *Postgres) UpdateProject(ctx context.Context, request model.UpdateProjectRequest) (*model.Project, error) {
+	query := `
+	UPDATE projects
+	SET name = $1
+	WHERE id = $2 AND removed = false
+	RETURNING id, name, removed, created_at`
+
+	var project model.Project
+
+	err := p.conn(ctx).QueryRow(
+		ctx,
+		query,
+		request.Name,
+		request.ID,
+	).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Removed,
+		&project.CreatedAt,
+	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, model.ErrProjectNotFound
+	case err != nil:
+		return nil, fmt.Errorf("p.conn(ctx).QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	return &project, nil
+}
+
+// DeleteProject marks the project as removed together with all of its goods
+// and returns the goods that were removed by the cascade with their values
+// before the removal, keyed by id.
+func (p *Postgres) DeleteProject(
+	ctx context.Context,
+	project model.Project,
+) (*model.Project, *[]model.Goods, map[int64]model.Goods, error) {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("DeleteProject/tx.Rollback(ctx)")
+		}
+	}()
+
+	query := `
+	UPDATE projects
+	SET removed = true
+	WHERE id = $1 AND removed = false
+	RETURNING id, name, removed, created_at`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		project.ID,
+	).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Removed,
+		&project.CreatedAt,
+	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil, nil, model.ErrProjectNotFound
+	case err != nil:
+		return nil, nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	query = fmt.Sprintf(`
+	WITH %s
+	UPDATE goods
+	SET removed = true, removed_at = now(), version = version + 1
+	FROM previous
+	WHERE project_id = $1 AND removed = false AND previous.previous_id = goods.id
+	RETURNING id, project_id, name, description, priority, removed, created_at, version,
+		%s`,
+		p.previousGoods(),
+		previousColumns)
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		project.ID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+
+	removedGoods := make([]model.Goods, 0)
+	previousGoods := make(map[int64]model.Goods)
+
+	for rows.Next() {
+		var (
+			good     model.Goods
+			previous previousValues
+		)
+
+		err = rows.Scan(append([]any{
+			&good.ID,
+			&good.ProjectID,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.CreatedAt,
+			&good.Version,
+		}, previous.scanTargets()...)...)
+		if err != nil {
+			rows.Close()
+
+			return nil, nil, nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		removedGoods = append(removedGoods, good)
+		previousGoods[good.ID] = previous.of(good)
+	}
+
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, nil, nil, fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return &project, &removedGoods, previousGoods, nil
+}
@@ -0,0 +1,414 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Saaghh/hezzl-hr/internal/config"
+	"github.com/Saaghh/hezzl-hr/internal/lexorank"
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+// goodsSource is the relation goods are read from. In lexorank mode the
+// priority of an active good is its position in the rank order of the project.
+func (p *Postgres) goodsSource() string {
+	if p.ranking != config.RankingLexorank {
+		return "goods"
+	}
+
+	return `(
+		SELECT id, project_id, name, description, removed, created_at, version, rank,
+			CASE WHEN removed THEN priority
+			ELSE (ROW_NUMBER() OVER (PARTITION BY project_id, removed ORDER BY rank, id))::int
+			END AS priority
+		FROM goods
+	) AS goods`
+}
+
+// orderColumn is the column that defines the order of goods within a project.
+func (p *Postgres) orderColumn() string {
+	if p.ranking == config.RankingLexorank {
+		return "rank"
+	}
+
+	return "priority"
+}
+
+// derivePriority replaces the stored priority of the good with its position
+// when priorities are derived from ranks.
+func (p *Postgres) derivePriority(ctx context.Context, tx pgx.Tx, goods *model.Goods) error {
+	if p.ranking != config.RankingLexorank {
+		return nil
+	}
+
+	query := fmt.Sprintf(`
+	SELECT priority
+	FROM %s
+	WHERE goods.id = $1 AND goods.project_id = $2`,
+		p.goodsSource())
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		goods.ID,
+		goods.ProjectID,
+	).Scan(&goods.Priority)
+	if err != nil {
+		return fmt.Errorf("tx.QueryRow(...).Scan(&goods.Priority): %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+func (p *Postgres) getGoodsByIDs(ctx context.Context, tx pgx.Tx, projectID int64, ids []int64) (*[]model.Goods, error) {
+	query := fmt.Sprintf(`
+	SELECT id, project_id, name, description, priority, removed, created_at, version
+	FROM %s
+	WHERE goods.project_id = $1 AND goods.id = ANY($2)
+	ORDER BY priority, id`,
+		p.goodsSource())
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		projectID,
+		ids)
+	if err != nil {
+		return nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	goods := make([]model.Goods, 0, len(ids))
+
+	for rows.Next() {
+		var good model.Goods
+
+		err = rows.Scan(
+			&good.ID,
+			&good.ProjectID,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.CreatedAt,
+			&good.Version)
+		if err != nil {
+			return nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		goods = append(goods, good)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	return &goods, nil
+}
+
+// moveGoods places the good at the requested position within its project and
+// returns every good whose priority has changed together with their values
+// before the move, keyed by id. In integer mode the goods in between are
+// renumbered, in lexorank mode only the rank of the moved good is rewritten.
+// The version of the moved good is raised only with bumpMoved, so a caller
+// that has changed the good already raises it once. The project must be
+// locked by the caller.
+func (p *Postgres) moveGoods(
+	ctx context.Context,
+	tx pgx.Tx,
+	goods model.UpdatePriorityRequest,
+	bumpMoved bool,
+) (*[]model.Goods, map[int64]model.Goods, error) {
+	query := fmt.Sprintf(`
+	SELECT goods.priority, (SELECT COUNT(*) FROM goods WHERE project_id = $2 AND removed = false)
+	FROM %s
+	WHERE goods.id = $1 AND goods.project_id = $2 AND goods.removed = false`,
+		p.goodsSource())
+
+	var current, activeGoods int
+
+	err := tx.QueryRow(
+		ctx,
+		query,
+		goods.ID,
+		goods.ProjectID,
+	).Scan(
+		&current,
+		&activeGoods,
+	)
+
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		return nil, nil, model.ErrGoodNotFound
+	case err != nil:
+		return nil, nil, fmt.Errorf("tx.QueryRow(...).Scan(...): %w", mapPgError(err))
+	}
+
+	// moving past the end of the list places the good last
+	position := min(goods.Priority, activeGoods)
+
+	if position == current {
+		return &[]model.Goods{}, map[int64]model.Goods{}, nil
+	}
+
+	rank, err := p.rankForPosition(ctx, tx, goods, position)
+	if errors.Is(err, lexorank.ErrInvalidRange) {
+		if err = p.rebalanceProject(ctx, tx, goods.ProjectID); err != nil {
+			return nil, nil, fmt.Errorf("p.rebalanceProject(ctx, tx, goods.ProjectID): %w", err)
+		}
+
+		rank, err = p.rankForPosition(ctx, tx, goods, position)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("p.rankForPosition(ctx, tx, goods, position): %w", err)
+	}
+
+	if p.ranking == config.RankingLexorank {
+		query = fmt.Sprintf(`
+		WITH %s
+		UPDATE goods
+		SET rank = $3, version = CASE WHEN $4 THEN goods.version + 1 ELSE goods.version END
+		FROM previous
+		WHERE goods.id = $2 AND goods.project_id = $1 AND previous.previous_id = goods.id
+		RETURNING goods.id, %s`,
+			p.previousGoods(),
+			previousColumns)
+
+		rows, err := tx.Query(ctx, query, goods.ProjectID, goods.ID, rank, bumpMoved)
+		if err != nil {
+			return nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+		}
+
+		// only the rank of the moved good is rewritten, the others keep theirs
+		return p.collectChangedGoods(ctx, tx, goods.ProjectID, rows)
+	}
+
+	// the rank is kept in order for a later switch to lexorank, the priority
+	// update below raises the version
+	query = `
+	UPDATE goods
+	SET rank = $1
+	WHERE id = $2 AND project_id = $3`
+
+	if _, err = tx.Exec(ctx, query, rank, goods.ID, goods.ProjectID); err != nil {
+		return nil, nil, fmt.Errorf("tx.Exec(...): %w", mapPgError(err))
+	}
+
+	query = fmt.Sprintf(`
+	WITH %s, ordered AS (
+		SELECT id, ROW_NUMBER() OVER (ORDER BY priority, id) AS position
+		FROM goods
+		WHERE project_id = $1 AND removed = false AND id <> $2
+	), target AS (
+		SELECT id, CASE WHEN position >= $3 THEN position + 1 ELSE position END AS priority
+		FROM ordered
+		UNION ALL
+		SELECT $2::bigint, $3::bigint
+	)
+	UPDATE goods
+	SET priority = target.priority,
+		version = CASE WHEN goods.id = $2 AND NOT $4 THEN goods.version ELSE goods.version + 1 END
+	FROM target
+	JOIN previous ON previous.previous_id = target.id
+	WHERE goods.id = target.id AND goods.project_id = $1 AND goods.priority <> target.priority
+	RETURNING goods.id, goods.project_id, goods.name, goods.description, goods.priority, goods.removed, goods.created_at, goods.version,
+		%s`,
+		p.previousGoods(),
+		previousColumns)
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		goods.ProjectID,
+		goods.ID,
+		position,
+		bumpMoved)
+	if err != nil {
+		return nil, nil, fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+	defer rows.Close()
+
+	changedGoods := make([]model.Goods, 0)
+	previousGoods := make(map[int64]model.Goods)
+
+	for rows.Next() {
+		var (
+			good     model.Goods
+			previous previousValues
+		)
+
+		err = rows.Scan(append([]any{
+			&good.ID,
+			&good.ProjectID,
+			&good.Name,
+			&good.Description,
+			&good.Priority,
+			&good.Removed,
+			&good.CreatedAt,
+			&good.Version,
+		}, previous.scanTargets()...)...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("rows.Scan(...): %w", mapPgError(err))
+		}
+
+		changedGoods = append(changedGoods, good)
+		previousGoods[good.ID] = previous.of(good)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("rows.Err(): %w", mapPgError(err))
+	}
+
+	return &changedGoods, previousGoods, nil
+}
+
+// rankForPosition returns a rank that puts the good at position among the
+// other active goods of its project.
+func (p *Postgres) rankForPosition(
+	ctx context.Context,
+	tx pgx.Tx,
+	goods model.UpdatePriorityRequest,
+	position int,
+) (string, error) {
+	query := fmt.Sprintf(`
+	SELECT rank
+	FROM goods
+	WHERE project_id = $1 AND removed = false AND id <> $2
+	ORDER BY %s, id
+	OFFSET $3 LIMIT 2`,
+		p.orderColumn())
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		goods.ProjectID,
+		goods.ID,
+		max(position-2, 0))
+	if err != nil {
+		return "", fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+
+	neighbours, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return "", fmt.Errorf("pgx.CollectRows(rows, pgx.RowTo[string]): %w", mapPgError(err))
+	}
+
+	var prev, next string
+
+	switch {
+	case position > 1 && len(neighbours) > 1:
+		prev, next = neighbours[0], neighbours[1]
+	case position > 1 && len(neighbours) == 1:
+		prev = neighbours[0]
+	case len(neighbours) > 0:
+		next = neighbours[0]
+	}
+
+	rank, err := lexorank.Between(prev, next)
+	if err != nil {
+		return "", fmt.Errorf("lexorank.Between(prev, next): %w", err)
+	}
+
+	return rank, nil
+}
+
+// RebalanceRanks respaces the ranks of every project whose ranks grew longer
+// than maxLength and returns the number of rebalanced projects. Positions of
+// goods are not changed.
+func (p *Postgres) RebalanceRanks(ctx context.Context, maxLength int) (int, error) {
+	query := `
+	SELECT DISTINCT project_id
+	FROM goods
+	WHERE removed = false AND length(rank) > $1`
+
+	rows, err := p.conn(ctx).Query(
+		ctx,
+		query,
+		maxLength)
+	if err != nil {
+		return 0, fmt.Errorf("p.conn(ctx).Query(...): %w", mapPgError(err))
+	}
+
+	projectIDs, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return 0, fmt.Errorf("pgx.CollectRows(rows, pgx.RowTo[int64]): %w", mapPgError(err))
+	}
+
+	for i, projectID := range projectIDs {
+		if err = p.rebalanceLockedProject(ctx, projectID); err != nil {
+			return i, fmt.Errorf("p.rebalanceLockedProject(ctx, projectID): %w", err)
+		}
+	}
+
+	return len(projectIDs), nil
+}
+
+func (p *Postgres) rebalanceLockedProject(ctx context.Context, projectID int64) error {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("rebalanceLockedProject/tx.Rollback(ctx)")
+		}
+	}()
+
+	if err = lockProject(ctx, tx, projectID); err != nil {
+		return fmt.Errorf("lockProject(ctx, tx, projectID): %w", err)
+	}
+
+	if err = p.rebalanceProject(ctx, tx, projectID); err != nil {
+		return fmt.Errorf("p.rebalanceProject(ctx, tx, projectID): %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return nil
+}
+
+// rebalanceProject rewrites the ranks of the active goods of the project with
+// evenly spaced ones, keeping their order. The project must be locked.
+func (p *Postgres) rebalanceProject(ctx context.Context, tx pgx.Tx, projectID int64) error {
+	query := fmt.Sprintf(`
+	SELECT id
+	FROM goods
+	WHERE project_id = $1 AND removed = false
+	ORDER BY %s, id`,
+		p.orderColumn())
+
+	rows, err := tx.Query(
+		ctx,
+		query,
+		projectID)
+	if err != nil {
+		return fmt.Errorf("tx.Query(...): %w", mapPgError(err))
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		return fmt.Errorf("pgx.CollectRows(rows, pgx.RowTo[int64]): %w", mapPgError(err))
+	}
+
+	query = `
+	UPDATE goods
+	SET rank = r.rank
+	FROM unnest($2::bigint[], $3::varchar[]) AS r(id, rank)
+	WHERE goods.id = r.id AND goods.project_id = $1`
+
+	if _, err = tx.Exec(ctx, query, projectID, ids, lexorank.Spread(len(ids))); err != nil {
+		return fmt.Errorf("tx.Exec(...): %w", mapPgError(err))
+	}
+
+	zap.L().Debug("rebalanced project ranks", zap.Int64("projectID", projectID), zap.Int("goods", len(ids)))
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package pg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+)
+
+// querier is implemented by both the pool and a transaction, so store methods
+// run the same way inside and outside of WithTx. Begin on a transaction opens
+// a savepoint.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+type txKey struct{}
+
+// conn returns the transaction carried by ctx or the pool.
+func (p *Postgres) conn(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+
+	return p.db
+}
+
+// WithTx runs fn in a transaction that every store call made with the passed
+// context joins. The transaction is committed when fn returns nil and rolled
+// back otherwise.
+func (p *Postgres) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx, err := p.conn(ctx).Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("p.conn(ctx).Begin(ctx): %w", mapPgError(err))
+	}
+
+	defer func() {
+		err := tx.Rollback(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			zap.L().With(zap.Error(err)).Warn("WithTx/tx.Rollback(ctx)")
+		}
+	}()
+
+	if err = fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("tx.Commit(ctx): %w", mapPgError(err))
+	}
+
+	return nil
+}
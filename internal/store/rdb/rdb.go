@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"strconv"
 	"time"
 
@@ -13,9 +14,15 @@ import (
 	"go.uber.org/zap"
 )
 
+// cacheKeyPatterns match every key of cached reads, idempotency records are
+// kept apart and survive invalidation.
+var cacheKeyPatterns = []string{"list:*", "good:*"}
+
 type Redis struct {
-	client         *redis.Client
-	defaultTimeout time.Duration
+	client                *redis.Client
+	defaultTimeout        time.Duration
+	idempotencyTTL        time.Duration
+	idempotencyPendingTTL time.Duration
 }
 
 func New(cfg *config.Config) *Redis {
@@ -26,16 +33,15 @@ func New(cfg *config.Config) *Redis {
 	})
 
 	return &Redis{
-		client:         client,
-		defaultTimeout: cfg.RedisDefaultTimeout,
+		client:                client,
+		defaultTimeout:        cfg.RedisDefaultTimeout,
+		idempotencyTTL:        cfg.IdempotencyTTL,
+		idempotencyPendingTTL: cfg.IdempotencyPendingTTL,
 	}
 }
 
-func (r *Redis) StoreGetResponse(ctx context.Context, response model.GetListResponse) error {
-	key := r.getParamsKey(model.ListParams{
-		Limit:  response.Meta.Limit,
-		Offset: response.Meta.Offset,
-	})
+func (r *Redis) StoreGetResponse(ctx context.Context, params model.ListParams, response model.GetListResponse) error {
+	key := r.getParamsKey(params)
 
 	serializedResponse, err := json.Marshal(response)
 	if err != nil {
@@ -52,8 +58,25 @@ func (r *Redis) StoreGetResponse(ctx context.Context, response model.GetListResp
 }
 
 func (r *Redis) InvalidateAllData(ctx context.Context) error {
-	if err := r.client.FlushDB(ctx).Err(); err != nil {
-		return fmt.Errorf("r.client.FlushDB(ctx).Err(): %w", err)
+	for _, pattern := range cacheKeyPatterns {
+		keys := make([]string, 0)
+
+		iter := r.client.Scan(ctx, 0, pattern, 100).Iterator()
+		for iter.Next(ctx) {
+			keys = append(keys, iter.Val())
+		}
+
+		if err := iter.Err(); err != nil {
+			return fmt.Errorf("iter.Err(): %w", err)
+		}
+
+		if len(keys) == 0 {
+			continue
+		}
+
+		if err := r.client.Unlink(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("r.client.Unlink(ctx, keys...).Err(): %w", err)
+		}
 	}
 
 	zap.L().Debug("successfully invalidated all data")
@@ -77,6 +100,121 @@ func (r *Redis) GetListResponse(ctx context.Context, params model.ListParams) (*
 	return &resultList, nil
 }
 
+func (r *Redis) StoreGood(ctx context.Context, goods model.Goods) error {
+	key := r.getGoodKey(goods)
+
+	serializedGoods, err := json.Marshal(goods)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(goods): %w", err)
+	}
+
+	if err = r.client.Set(ctx, key, serializedGoods, r.defaultTimeout).Err(); err != nil {
+		return fmt.Errorf("r.client.Set(ctx, key, serializedGoods, r.defaultTimeout).Err(): %w", err)
+	}
+
+	zap.L().Debug("successfully saved good to redis", zap.String("key", key))
+
+	return nil
+}
+
+func (r *Redis) GetGood(ctx context.Context, goods model.Goods) (*model.Goods, error) {
+	res, err := r.client.Get(ctx, r.getGoodKey(goods)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("r.client.Get(ctx, r.getGoodKey(goods)).Result(): %w", err)
+	}
+
+	var result model.Goods
+	if err = json.Unmarshal([]byte(res), &result); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal([]byte(res), &result): %w", err)
+	}
+
+	zap.L().Debug("successfully returned good from redis", zap.Int64("id", result.ID))
+
+	return &result, nil
+}
+
+// ReserveIdempotencyKey stores a pending record for the key, which expires
+// after the pending TTL unless the request stores its outcome. When the key is
+// already taken the stored record is returned instead.
+func (r *Redis) ReserveIdempotencyKey(ctx context.Context, key string, requestHash string) (*model.IdempotencyRecord, error) {
+	pending, err := json.Marshal(model.IdempotencyRecord{RequestHash: requestHash})
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal(...): %w", err)
+	}
+
+	reserved, err := r.client.SetNX(ctx, r.getIdempotencyKey(key), pending, r.idempotencyPendingTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("r.client.SetNX(...).Result(): %w", err)
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	res, err := r.client.Get(ctx, r.getIdempotencyKey(key)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("r.client.Get(ctx, r.getIdempotencyKey(key)).Result(): %w", err)
+	}
+
+	var record model.IdempotencyRecord
+	if err = json.Unmarshal([]byte(res), &record); err != nil {
+		return nil, fmt.Errorf("json.Unmarshal([]byte(res), &record): %w", err)
+	}
+
+	return &record, nil
+}
+
+func (r *Redis) StoreIdempotencyRecord(ctx context.Context, key string, record model.IdempotencyRecord) error {
+	serializedRecord, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("json.Marshal(record): %w", err)
+	}
+
+	err = r.client.Set(ctx, r.getIdempotencyKey(key), serializedRecord, r.idempotencyTTL).Err()
+	if err != nil {
+		return fmt.Errorf("r.client.Set(...).Err(): %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) DeleteIdempotencyKey(ctx context.Context, key string) error {
+	if err := r.client.Del(ctx, r.getIdempotencyKey(key)).Err(); err != nil {
+		return fmt.Errorf("r.client.Del(ctx, r.getIdempotencyKey(key)).Err(): %w", err)
+	}
+
+	return nil
+}
+
+func (r *Redis) getIdempotencyKey(key string) string {
+	return "idempotency:" + key
+}
+
+func (r *Redis) getGoodKey(goods model.Goods) string {
+	return "good:" + strconv.FormatInt(goods.ProjectID, 10) + ":" + strconv.FormatInt(goods.ID, 10)
+}
+
 func (r *Redis) getParamsKey(params model.ListParams) string {
-	return strconv.Itoa(params.Offset) + "-" + strconv.Itoa(params.Limit)
+	values := url.Values{}
+
+	values.Set("limit", strconv.Itoa(params.Limit))
+	values.Set("offset", strconv.Itoa(params.Offset))
+	values.Set("projectId", strconv.FormatInt(params.ProjectID, 10))
+	values.Set("name", params.Name)
+	values.Set("nameMatch", params.NameMatch)
+	values.Set("priorityFrom", strconv.Itoa(params.PriorityFrom))
+	values.Set("priorityTo", strconv.Itoa(params.PriorityTo))
+	values.Set("includeRemoved", strconv.FormatBool(params.IncludeRemoved))
+	values.Set("sort", params.Sort)
+	values.Set("cursor", params.Cursor)
+
+	if params.CreatedFrom != nil {
+		values.Set("createdFrom", params.CreatedFrom.UTC().Format(time.RFC3339Nano))
+	}
+
+	if params.CreatedTo != nil {
+		values.Set("createdTo", params.CreatedTo.UTC().Format(time.RFC3339Nano))
+	}
+
+	return "list:" + values.Encode()
 }
@@ -0,0 +1,100 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+const parallelCreates = 300
+
+func (s *IntegrationTestSuite) TestConcurrentCreatePriorities() {
+	project := s.createProject("concurrent project")
+
+	var wg sync.WaitGroup
+
+	errs := make(chan error, parallelCreates)
+
+	for i := 0; i < parallelCreates; i++ {
+		wg.Add(1)
+
+		go func(i int) {
+			defer wg.Done()
+
+			errs <- postGood(context.Background(), project.ID, "parallel good "+strconv.Itoa(i))
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		s.Require().NoError(err)
+	}
+
+	var responseData model.GetListResponse
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodGet,
+		getEndpoint,
+		nil,
+		&responseData,
+		QueryListParams{
+			Limit:     parallelCreates,
+			ProjectID: project.ID,
+		},
+	)
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Len(responseData.GoodsList, parallelCreates)
+
+	priorities := make(map[int]bool, parallelCreates)
+
+	for _, goods := range responseData.GoodsList {
+		s.Require().False(priorities[goods.Priority], "duplicate priority %d", goods.Priority)
+		priorities[goods.Priority] = true
+	}
+
+	for priority := 1; priority <= parallelCreates; priority++ {
+		s.Require().True(priorities[priority], "missing priority %d", priority)
+	}
+}
+
+// postGood creates a good without touching the suite assertions, so it is safe
+// to call from many goroutines at once.
+func postGood(ctx context.Context, projectID int64, name string) error {
+	reqBody, err := json.Marshal(model.Goods{Name: name})
+	if err != nil {
+		return fmt.Errorf("json.Marshal(...): %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		bindAddr+createEndpoint+"?projectId="+strconv.FormatInt(projectID, 10),
+		bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("http.NewRequestWithContext(...): %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("http.DefaultClient.Do(req): %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/Saaghh/hezzl-hr/internal/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigRejectsSpinningOutbox(t *testing.T) {
+	t.Setenv("OUTBOX_BATCH_SIZE", "0")
+
+	require.PanicsWithValue(t, "invalid config: OUTBOX_BATCH_SIZE must be positive", func() {
+		config.New()
+	})
+
+	t.Setenv("OUTBOX_BATCH_SIZE", "100")
+	t.Setenv("OUTBOX_RELAY_INTERVAL", "0s")
+
+	require.PanicsWithValue(t, "invalid config: OUTBOX_RELAY_INTERVAL must be positive", func() {
+		config.New()
+	})
+}
+
+func TestConfigRejectsSpinningPurger(t *testing.T) {
+	t.Setenv("PURGE_INTERVAL", "0s")
+
+	require.PanicsWithValue(t, "invalid config: PURGE_INTERVAL must be positive", func() {
+		config.New()
+	})
+
+	t.Setenv("PURGE_INTERVAL", "1h")
+	t.Setenv("PURGE_BATCH_SIZE", "-1")
+
+	require.PanicsWithValue(t, "invalid config: PURGE_BATCH_SIZE must be positive", func() {
+		config.New()
+	})
+}
+
+func TestConfigRejectsInvalidRanks(t *testing.T) {
+	t.Setenv("RANK_REBALANCE_INTERVAL", "0s")
+
+	require.PanicsWithValue(t, "invalid config: RANK_REBALANCE_INTERVAL must be positive", func() {
+		config.New()
+	})
+
+	t.Setenv("RANK_REBALANCE_INTERVAL", "5m")
+	t.Setenv("RANK_MAX_LENGTH", "0")
+
+	require.PanicsWithValue(t, "invalid config: RANK_MAX_LENGTH must be positive", func() {
+		config.New()
+	})
+}
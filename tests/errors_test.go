@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/apiserver"
+	"github.com/Saaghh/hezzl-hr/internal/model"
+)
+
+const forcedConflictName = "forced conflict good"
+
+func (s *IntegrationTestSuite) TestErrorMapping() {
+	ctx := context.Background()
+
+	s.Run("create in unknown project", func() {
+		var response apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			ctx,
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: "orphan good"},
+			&response,
+			QueryRequestParams{ProjectID: -1},
+		)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+		s.Require().Equal(4, response.Code)
+	})
+
+	for _, code := range []string{"23P01", "40001"} {
+		s.Run("forced "+code, func() {
+			s.forceGoodsInsertError(ctx, code)
+
+			var response apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				ctx,
+				http.MethodPost,
+				createEndpoint,
+				model.Goods{Name: forcedConflictName},
+				&response,
+				QueryRequestParams{ProjectID: s.standardProjectID},
+			)
+
+			s.Require().Equal(http.StatusConflict, resp.StatusCode)
+			s.Require().Equal(9, response.Code)
+		})
+	}
+}
+
+func (s *IntegrationTestSuite) TestIdempotencyConflictRetried() {
+	ctx := context.Background()
+
+	// keys outlive the tables, so every run needs its own
+	headers := map[string]string{"Idempotency-Key": fmt.Sprintf("conflict-%d", time.Now().UnixNano())}
+	params := QueryRequestParams{ProjectID: s.standardProjectID}
+
+	s.Run("409, serialization failure", func() {
+		s.forceGoodsInsertError(ctx, "40001")
+
+		resp := s.sendRequestWithHeaders(
+			ctx,
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: forcedConflictName},
+			nil,
+			params,
+			headers)
+
+		s.Require().Equal(http.StatusConflict, resp.StatusCode)
+	})
+
+	s.Run("201, retried with the same key", func() {
+		var created model.Goods
+
+		resp := s.sendRequestWithHeaders(
+			ctx,
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: forcedConflictName},
+			&created,
+			params,
+			headers)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().Equal(forcedConflictName, created.Name)
+	})
+}
+
+// forceGoodsInsertError makes inserts of goods named forcedConflictName fail
+// with the given SQLSTATE until the end of the test.
+func (s *IntegrationTestSuite) forceGoodsInsertError(ctx context.Context, code string) {
+	db := s.connect(ctx)
+
+	_, err := db.Exec(ctx, fmt.Sprintf(`
+CREATE OR REPLACE FUNCTION force_goods_insert_error() RETURNS trigger AS $$
+BEGIN
+	IF NEW.name = '%s' THEN
+		RAISE EXCEPTION 'forced error' USING ERRCODE = '%s';
+	END IF;
+
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER force_goods_insert_error BEFORE INSERT ON goods
+	FOR EACH ROW EXECUTE FUNCTION force_goods_insert_error();`,
+		forcedConflictName, code))
+	s.Require().NoError(err)
+
+	s.T().Cleanup(func() {
+		_, err := db.Exec(ctx, `
+DROP TRIGGER IF EXISTS force_goods_insert_error ON goods;
+DROP FUNCTION IF EXISTS force_goods_insert_error();`)
+		s.Require().NoError(err)
+
+		db.Close()
+	})
+}
@@ -0,0 +1,43 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoodsEventJSON(t *testing.T) {
+	previous := model.Goods{ID: 1, ProjectID: 2, Name: "old", Priority: 1, Version: 1}
+	current := model.Goods{ID: 1, ProjectID: 2, Name: "new", Priority: 1, Version: 2}
+
+	event := model.NewGoodsEvent(model.EventTypeUpdated, &previous, &current)
+
+	data, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	var decoded model.GoodsEvent
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	require.NotEqual(t, uuid.Nil, decoded.EventID)
+	require.Equal(t, event.EventID, decoded.EventID)
+	require.Equal(t, model.EventSchemaVersion, decoded.SchemaVersion)
+	require.Equal(t, model.EventTypeUpdated, decoded.Type)
+	require.Equal(t, previous, *decoded.Previous)
+	require.Equal(t, current, decoded.Goods())
+}
+
+func TestGoodsEventLegacyJSON(t *testing.T) {
+	data := []byte(`{"id":3,"projectId":1,"name":"good","priority":2,"removed":true,` +
+		`"type":"purged","eventTime":"2024-03-10T12:00:00Z"}`)
+
+	var decoded model.GoodsEvent
+	require.NoError(t, json.Unmarshal(data, &decoded))
+
+	require.Equal(t, 1, decoded.SchemaVersion)
+	require.Equal(t, model.EventTypePurged, decoded.Type)
+	require.Nil(t, decoded.Previous)
+	require.Equal(t, model.Goods{ID: 3, ProjectID: 1, Name: "good", Priority: 2, Removed: true}, decoded.Goods())
+}
@@ -4,10 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
 	"os/signal"
+	"strings"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/Saaghh/hezzl-hr/internal/apiserver"
 	"github.com/Saaghh/hezzl-hr/internal/config"
@@ -18,18 +22,34 @@ import (
 	"github.com/Saaghh/hezzl-hr/internal/store/pg"
 	"github.com/Saaghh/hezzl-hr/internal/store/rdb"
 	"github.com/google/go-querystring/query"
+	"github.com/jackc/pgx/v5/pgxpool"
 	migrate "github.com/rubenv/sql-migrate"
 	"github.com/stretchr/testify/suite"
 	"go.uber.org/zap"
 )
 
 const (
-	bindAddr         = "http://localhost:8080/api/v1"
-	createEndpoint   = "/good/create"
-	updateEndpoint   = "/good/update"
-	deleteEndpoint   = "/good/remove"
-	getEndpoint      = "/good/list"
-	priorityEndpoint = "/good/reprioritize"
+	bindAddr           = "http://localhost:8080/api/v1"
+	createEndpoint     = "/good/create"
+	bulkCreateEndpoint = "/good/bulk-create"
+	bulkRemoveEndpoint = "/good/bulk-remove"
+	bulkUpdateEndpoint = "/good/bulk-update"
+	updateEndpoint     = "/good/update"
+	deleteEndpoint     = "/good/remove"
+	restoreEndpoint    = "/good/restore"
+	getEndpoint        = "/good/list"
+	getGoodEndpoint    = "/good/get"
+	trashEndpoint      = "/good/trash"
+	priorityEndpoint   = "/good/reprioritize"
+	reorderEndpoint    = "/good/reorder"
+	batchEndpoint      = "/batch"
+	healthEndpoint     = "/health"
+
+	createProjectEndpoint = "/project/create"
+	updateProjectEndpoint = "/project/update"
+	deleteProjectEndpoint = "/project/remove"
+	getProjectEndpoint    = "/project/get"
+	listProjectEndpoint   = "/project/list"
 )
 
 type QueryRequestParams struct {
@@ -37,9 +57,19 @@ type QueryRequestParams struct {
 	ProjectID int64 `url:"projectId"`
 }
 
+type QueryProjectParams struct {
+	ID int64 `url:"id"`
+}
+
 type QueryListParams struct {
-	Offset int `url:"offset"`
-	Limit  int `url:"limit"`
+	Offset         int    `url:"offset"`
+	Limit          int    `url:"limit"`
+	ProjectID      int64  `url:"projectId,omitempty"`
+	Name           string `url:"name,omitempty"`
+	NameMatch      string `url:"nameMatch,omitempty"`
+	IncludeRemoved bool   `url:"includeRemoved,omitempty"`
+	Sort           string `url:"sort,omitempty"`
+	Cursor         string `url:"cursor,omitempty"`
 }
 
 type IntegrationTestSuite struct {
@@ -79,7 +109,7 @@ func (s *IntegrationTestSuite) SetupSuite() {
 
 	cashdb := rdb.New(cfg)
 
-	mb, err := nats.NewPublisher()
+	mb, err := nats.NewPublisher(cfg)
 
 	serviceLayer := service.New(pgStore, cashdb, mb)
 
@@ -88,6 +118,8 @@ func (s *IntegrationTestSuite) SetupSuite() {
 
 	s.standardProjectID = project.ID
 
+	go serviceLayer.RunOutboxRelay(ctx, cfg.OutboxRelayInterval, cfg.OutboxBatchSize, cfg.OutboxRetryDelay, cfg.OutboxMaxRetryDelay)
+
 	server := apiserver.New(
 		apiserver.Config{BindAddress: cfg.BindAddress},
 		serviceLayer)
@@ -127,7 +159,7 @@ func (s *IntegrationTestSuite) TestAPI() {
 				context.Background(),
 				http.MethodPatch,
 				updateEndpoint,
-				goods1,
+				map[string]any{"name": goods1.Name},
 				nil,
 				QueryRequestParams{
 					ID:        goods1.ID,
@@ -206,6 +238,45 @@ func (s *IntegrationTestSuite) TestAPI() {
 		})
 	})
 
+	s.Run("GET:/good/get", func() {
+		s.Run("404", func() {
+			var responseData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getGoodEndpoint,
+				nil,
+				&responseData,
+				QueryRequestParams{
+					ID:        -1,
+					ProjectID: s.standardProjectID,
+				})
+
+			s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+			s.Require().Equal("errors.good.notFound", responseData.Message)
+		})
+
+		s.Run("200", func() {
+			var result model.Goods
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getGoodEndpoint,
+				nil,
+				&result,
+				QueryRequestParams{
+					ID:        goods1.ID,
+					ProjectID: goods1.ProjectID,
+				})
+
+			s.Require().Equal(http.StatusOK, resp.StatusCode)
+			s.Require().Equal(goods1.Name, result.Name)
+			s.Require().Equal(goods1.Description, result.Description)
+		})
+	})
+
 	s.Run("DELETE:/good/delete", func() {
 		s.Run("404", func() {
 			var responseData apiserver.ErrorResponse
@@ -243,6 +314,19 @@ func (s *IntegrationTestSuite) TestAPI() {
 			s.Require().Equal(goods1.ID, deletedGood.ID)
 			s.Require().Equal(goods1.ProjectID, deletedGood.ProjectID)
 			s.Require().Equal(true, deletedGood.Removed)
+
+			resp = s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getGoodEndpoint,
+				nil,
+				nil,
+				QueryRequestParams{
+					ID:        goods1.ID,
+					ProjectID: goods1.ProjectID,
+				})
+
+			s.Require().Equal(http.StatusNotFound, resp.StatusCode)
 		})
 	})
 
@@ -256,15 +340,86 @@ func (s *IntegrationTestSuite) TestAPI() {
 			nil,
 			&responseData,
 			QueryListParams{
-				Offset: 0,
-				Limit:  10,
+				Offset:    0,
+				Limit:     10,
+				ProjectID: s.standardProjectID,
 			},
 		)
 
 		s.Require().Equal(http.StatusOK, resp.StatusCode)
-		s.Require().Equal(3, responseData.Meta.Total)
+		s.Require().Equal(2, responseData.Meta.Total)
+		s.Require().Equal(2, responseData.Meta.Active)
 		s.Require().Equal(1, responseData.Meta.Removed)
+		s.Require().False(responseData.Meta.HasMore)
 		s.Require().Equal(2, cap(*responseData.Goods))
+
+		s.Run("filter by name", func() {
+			var filteredData apiserver.GetListResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getEndpoint,
+				nil,
+				&filteredData,
+				QueryListParams{
+					Limit:     10,
+					ProjectID: s.standardProjectID,
+					Name:      "THIRD",
+					NameMatch: model.NameMatchPrefix,
+				},
+			)
+
+			s.Require().Equal(http.StatusOK, resp.StatusCode)
+			s.Require().Equal(1, filteredData.Meta.Total)
+			s.Require().Len(*filteredData.Goods, 1)
+			s.Require().Equal(goods3.ID, (*filteredData.Goods)[0].ID)
+		})
+
+		s.Run("sort and include removed", func() {
+			var sortedData apiserver.GetListResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getEndpoint,
+				nil,
+				&sortedData,
+				QueryListParams{
+					Limit:          10,
+					ProjectID:      s.standardProjectID,
+					IncludeRemoved: true,
+					Sort:           model.SortPriorityDesc,
+				},
+			)
+
+			s.Require().Equal(http.StatusOK, resp.StatusCode)
+			s.Require().Equal(3, sortedData.Meta.Total)
+			s.Require().Len(*sortedData.Goods, 3)
+			s.Require().Equal(goods3.ID, (*sortedData.Goods)[0].ID)
+			s.Require().Equal(goods1.ID, (*sortedData.Goods)[2].ID)
+		})
+
+		s.Run("400, wrong sort", func() {
+			var errorData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodGet,
+				getEndpoint,
+				nil,
+				&errorData,
+				QueryListParams{
+					Limit: 10,
+					Sort:  "random",
+				},
+			)
+
+			s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+			s.Require().Equal(8, errorData.Code)
+			s.Require().Equal("errors.validation", errorData.Message)
+			s.Require().Equal(map[string]any{"sort": model.CodeUnknownValue}, errorData.Details)
+		})
 	})
 
 	s.Run("PATCH:/good/reprioritize", func() {
@@ -286,51 +441,1689 @@ func (s *IntegrationTestSuite) TestAPI() {
 	})
 }
 
-func (s *IntegrationTestSuite) createGood(name string) *model.Goods {
-	var goods model.Goods
+func (s *IntegrationTestSuite) TestProjectAPI() {
+	var project model.Project
 
-	resp := s.sendRequest(
-		context.Background(),
-		http.MethodPost,
-		createEndpoint,
-		model.Goods{Name: name},
-		&goods,
-		QueryRequestParams{ProjectID: s.standardProjectID},
+	s.Run("POST:/project/create", func() {
+		s.Run("400, empty name", func() {
+			var responseData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodPost,
+				createProjectEndpoint,
+				model.Project{},
+				&responseData,
+				QueryRequestParams{})
+
+			s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+			s.Require().Equal(8, responseData.Code)
+			s.Require().Equal(map[string]any{"name": model.CodeRequired}, responseData.Details)
+		})
+
+		s.Run("400, long name", func() {
+			var responseData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodPost,
+				createProjectEndpoint,
+				model.Project{Name: strings.Repeat("n", model.MaxNameLength+1)},
+				&responseData,
+				QueryRequestParams{})
+
+			s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+			s.Require().Equal(map[string]any{"name": model.CodeTooLong}, responseData.Details)
+		})
+
+		s.Run("201", func() {
+			project = *s.createProject("second project")
+
+			s.Require().NotEqual(0, project.ID)
+			s.Require().Equal("second project", project.Name)
+		})
+	})
+
+	s.Run("PATCH:/project/update", func() {
+		s.Run("400, long name", func() {
+			var responseData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodPatch,
+				updateProjectEndpoint,
+				model.UpdateProjectRequest{Name: strings.Repeat("n", model.MaxNameLength+1)},
+				&responseData,
+				QueryProjectParams{ID: project.ID})
+
+			s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+			s.Require().Equal(map[string]any{"name": model.CodeTooLong}, responseData.Details)
+		})
+
+		s.Run("404", func() {
+			var responseData apiserver.ErrorResponse
+
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodPatch,
+				updateProjectEndpoint,
+				model.UpdateProjectRequest{Name: "any"},
+				&responseData,
+				QueryProjectParams{ID: -1})
+
+			s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+			s.Require().Equal("errors.project.notFound", responseData.Message)
+		})
+
+		s.Run("200", func() {
+			resp := s.sendRequest(
+				context.Background(),
+				http.MethodPatch,
+				updateProjectEndpoint,
+				model.UpdateProjectRequest{Name: "renamed project"},
+				&project,
+				QueryProjectParams{ID: project.ID})
+
+			s.Require().Equal(http.StatusOK, resp.StatusCode)
+			s.Require().Equal("renamed project", project.Name)
+		})
+	})
+
+	s.Run("GET:/project/get", func() {
+		var result model.Project
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getProjectEndpoint,
+			nil,
+			&result,
+			QueryProjectParams{ID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(project.Name, result.Name)
+	})
+
+	s.Run("GET:/project/list", func() {
+		var responseData apiserver.ProjectListResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			listProjectEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{Limit: 100})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().GreaterOrEqual(len(*responseData.Projects), 2)
+	})
+
+	s.Run("400, negative limit", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			listProjectEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{Limit: -1})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{"limit": model.CodeOutOfRange}, responseData.Details)
+	})
+
+	s.Run("DELETE:/project/remove", func() {
+		var (
+			deletedProject model.Project
+			deletedGood    model.Goods
+		)
+
+		goods := s.createGoodInProject("doomed good", project.ID)
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodDelete,
+			deleteProjectEndpoint,
+			nil,
+			&deletedProject,
+			QueryProjectParams{ID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().True(deletedProject.Removed)
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodDelete,
+			deleteEndpoint,
+			nil,
+			&deletedGood,
+			QueryRequestParams{
+				ID:        goods.ID,
+				ProjectID: goods.ProjectID,
+			})
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getProjectEndpoint,
+			nil,
+			nil,
+			QueryProjectParams{ID: project.ID})
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func (s *IntegrationTestSuite) TestCursorPagination() {
+	project := s.createProject("cursor project")
+
+	created := make(map[int64]bool)
+
+	for i := 0; i < 5; i++ {
+		goods := s.createGoodInProject("paged good", project.ID)
+		created[goods.ID] = true
+	}
+
+	var (
+		cursor string
+		pages  int
 	)
 
-	s.Require().Equal(http.StatusCreated, resp.StatusCode)
-	s.Require().NotEqual(0, goods.ID)
-	s.Require().Equal(name, goods.Name)
+	seen := make(map[int64]bool)
 
-	return &goods
+	for {
+		var responseData model.GetListResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{
+				Limit:     2,
+				ProjectID: project.ID,
+				Cursor:    cursor,
+			},
+		)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		pages++
+
+		for _, goods := range responseData.GoodsList {
+			s.Require().False(seen[goods.ID])
+			seen[goods.ID] = true
+		}
+
+		if !responseData.Meta.HasMore {
+			s.Require().Empty(responseData.Meta.NextCursor)
+
+			break
+		}
+
+		s.Require().NotEmpty(responseData.Meta.NextCursor)
+		cursor = responseData.Meta.NextCursor
+	}
+
+	s.Require().Equal(3, pages)
+	s.Require().Equal(created, seen)
+
+	s.Run("400, invalid cursor", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			nil,
+			QueryListParams{
+				Limit:  2,
+				Cursor: "not a cursor",
+			},
+		)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
 }
 
-func (s *IntegrationTestSuite) sendRequest(ctx context.Context, method, endpoint string, body interface{}, dest interface{}, params any) *http.Response {
-	s.T().Helper()
+func (s *IntegrationTestSuite) TestProjectScopedPriorities() {
+	firstProject := s.createProject("first scoped project")
+	secondProject := s.createProject("second scoped project")
 
-	reqBody, err := json.Marshal(body)
-	s.Require().NoError(err)
+	first := s.createGoodInProject("first", firstProject.ID)
+	second := s.createGoodInProject("second", firstProject.ID)
+	other := s.createGoodInProject("other", secondProject.ID)
 
-	queryParamsValues, err := query.Values(params)
-	s.Require().NoError(err)
+	s.Require().Equal(1, first.Priority)
+	s.Require().Equal(2, second.Priority)
+	s.Require().Equal(1, other.Priority)
 
-	req, err := http.NewRequestWithContext(ctx, method, bindAddr+endpoint+"?"+queryParamsValues.Encode(), bytes.NewReader(reqBody))
-	s.Require().NoError(err)
+	var responseData apiserver.ReprioritizeResponse
 
-	req.Header.Set("Content-Type", "application/json")
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodPatch,
+		priorityEndpoint,
+		model.UpdatePriorityRequest{Priority: 1},
+		&responseData,
+		QueryRequestParams{
+			ID:        second.ID,
+			ProjectID: second.ProjectID,
+		})
 
-	resp, err := http.DefaultClient.Do(req)
-	s.Require().NoError(err)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
 
-	defer func() {
-		err = resp.Body.Close()
-		s.Require().NoError(err)
-	}()
+	for _, goods := range *responseData.Priorities {
+		s.Require().Equal(firstProject.ID, goods.ProjectID)
+	}
 
-	if dest != nil {
-		err = json.NewDecoder(resp.Body).Decode(&dest)
-		s.Require().NoError(err)
+	var otherGood model.Goods
+
+	resp = s.sendRequest(
+		context.Background(),
+		http.MethodGet,
+		getGoodEndpoint,
+		nil,
+		&otherGood,
+		QueryRequestParams{
+			ID:        other.ID,
+			ProjectID: other.ProjectID,
+		})
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal(1, otherGood.Priority)
+}
+
+func (s *IntegrationTestSuite) TestReprioritizeMove() {
+	project := s.createProject("move project")
+
+	goods := make([]*model.Goods, 0, 5)
+	for i := 0; i < 5; i++ {
+		goods = append(goods, s.createGoodInProject("movable good", project.ID))
 	}
 
-	return resp
+	s.Run("move down", func() {
+		changed := s.reprioritize(goods[1], 4)
+
+		s.Require().Len(changed, 3)
+		s.Require().Equal(4, changed[goods[1].ID])
+		s.Require().Equal(2, changed[goods[2].ID])
+		s.Require().Equal(3, changed[goods[3].ID])
+	})
+
+	s.Run("move up", func() {
+		changed := s.reprioritize(goods[4], 1)
+
+		s.Require().Len(changed, 5)
+		s.Require().Equal(1, changed[goods[4].ID])
+		s.Require().Equal(2, changed[goods[0].ID])
+	})
+
+	s.Run("move past the end", func() {
+		changed := s.reprioritize(goods[4], 100)
+
+		s.Require().Equal(5, changed[goods[4].ID])
+	})
+
+	s.Run("same position", func() {
+		changed := s.reprioritize(goods[4], 5)
+
+		s.Require().Empty(changed)
+	})
+
+	var responseData model.GetListResponse
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodGet,
+		getEndpoint,
+		nil,
+		&responseData,
+		QueryListParams{
+			Limit:     10,
+			ProjectID: project.ID,
+		},
+	)
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	for i, good := range responseData.GoodsList {
+		s.Require().Equal(i+1, good.Priority)
+	}
+}
+
+func (s *IntegrationTestSuite) TestRestore() {
+	project := s.createProject("restore project")
+
+	restored := s.createGoodInProject("restored good", project.ID)
+	s.createGoodInProject("kept good", project.ID)
+
+	s.Run("404, not removed", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			restoreEndpoint,
+			nil,
+			nil,
+			QueryRequestParams{
+				ID:        restored.ID,
+				ProjectID: restored.ProjectID,
+			})
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodDelete,
+		deleteEndpoint,
+		nil,
+		nil,
+		QueryRequestParams{
+			ID:        restored.ID,
+			ProjectID: restored.ProjectID,
+		})
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	s.Run("200", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			restoreEndpoint,
+			nil,
+			restored,
+			QueryRequestParams{
+				ID:        restored.ID,
+				ProjectID: restored.ProjectID,
+			})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().False(restored.Removed)
+		s.Require().Equal(3, restored.Priority)
+	})
+}
+
+func (s *IntegrationTestSuite) TestTrash() {
+	project := s.createProject("trash project")
+
+	removed := s.createGoodInProject("trashed good", project.ID)
+	s.createGoodInProject("kept good", project.ID)
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodDelete,
+		deleteEndpoint,
+		nil,
+		nil,
+		QueryRequestParams{
+			ID:        removed.ID,
+			ProjectID: removed.ProjectID,
+		})
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	var responseData model.GetListResponse
+
+	resp = s.sendRequest(
+		context.Background(),
+		http.MethodGet,
+		trashEndpoint,
+		nil,
+		&responseData,
+		QueryListParams{
+			Limit:     10,
+			ProjectID: project.ID,
+		})
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal(1, responseData.Meta.Total)
+	s.Require().Len(responseData.GoodsList, 1)
+	s.Require().Equal(removed.ID, responseData.GoodsList[0].ID)
+	s.Require().NotNil(responseData.GoodsList[0].RemovedAt)
+
+	s.Run("offset past the end", func() {
+		var trash model.GetListResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			trashEndpoint,
+			nil,
+			&trash,
+			QueryListParams{
+				Offset:    10,
+				Limit:     10,
+				ProjectID: project.ID,
+			})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Empty(trash.GoodsList)
+		s.Require().Equal(1, trash.Meta.Total)
+		s.Require().False(trash.Meta.HasMore)
+	})
+
+	s.Run("purge", func() {
+		purged, err := s.store.PurgeRemovedGoods(context.Background(), time.Now().Add(time.Minute), 1000)
+		s.Require().NoError(err)
+		s.Require().NotEmpty(*purged)
+
+		var trash model.GetListResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			trashEndpoint,
+			nil,
+			&trash,
+			QueryListParams{
+				Limit:     10,
+				ProjectID: project.ID,
+			})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Empty(trash.GoodsList)
+	})
+}
+
+func (s *IntegrationTestSuite) TestReorder() {
+	project := s.createProject("reorder project")
+
+	goods := make([]*model.Goods, 0, 4)
+	for i := 0; i < 4; i++ {
+		goods = append(goods, s.createGoodInProject("reordered good", project.ID))
+	}
+
+	s.Run("400, no project", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			reorderEndpoint,
+			model.ReorderRequest{IDs: []int64{goods[0].ID}},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(8, responseData.Code)
+		s.Require().Equal(map[string]any{"projectId": model.CodeRequired}, responseData.Details)
+	})
+
+	s.Run("404, unknown project", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			reorderEndpoint,
+			model.ReorderRequest{ProjectID: -1, IDs: []int64{goods[0].ID}},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+		s.Require().Equal(4, responseData.Code)
+	})
+
+	s.Run("400, duplicates", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			reorderEndpoint,
+			model.ReorderRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{goods[0].ID, goods[0].ID},
+			},
+			nil,
+			QueryRequestParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("404, unknown good", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			reorderEndpoint,
+			model.ReorderRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{goods[0].ID, -1},
+			},
+			nil,
+			QueryRequestParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("200", func() {
+		var responseData apiserver.ReprioritizeResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			reorderEndpoint,
+			model.ReorderRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{goods[3].ID, goods[1].ID},
+			},
+			&responseData,
+			QueryRequestParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(*responseData.Priorities, 2)
+
+		changed := make(map[int64]int)
+		for _, good := range *responseData.Priorities {
+			changed[good.ID] = good.Priority
+		}
+
+		s.Require().Equal(2, changed[goods[3].ID])
+		s.Require().Equal(4, changed[goods[1].ID])
+	})
+}
+
+func (s *IntegrationTestSuite) TestBulkCreate() {
+	project := s.createProject("bulk project")
+	existing := s.createGoodInProject("existing good", project.ID)
+
+	s.Run("404, unknown project", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkCreateEndpoint,
+			model.BulkCreateRequest{
+				ProjectID: -1,
+				Goods:     []model.Goods{{Name: "orphan"}},
+			},
+			nil,
+			nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+	})
+
+	s.Run("200, per-item results", func() {
+		var responseData apiserver.BulkResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkCreateEndpoint,
+			model.BulkCreateRequest{
+				ProjectID: project.ID,
+				Goods: []model.Goods{
+					{Name: "first", Description: "first description"},
+					{Name: ""},
+					{Name: "second"},
+				},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		results := *responseData.Results
+		s.Require().Len(results, 3)
+
+		s.Require().Equal(0, results[0].Index)
+		s.Require().Empty(results[0].Error)
+		s.Require().Equal("first", results[0].Goods.Name)
+		s.Require().Equal("first description", results[0].Goods.Description)
+		s.Require().Equal(project.ID, results[0].Goods.ProjectID)
+		s.Require().Equal(existing.Priority+1, results[0].Goods.Priority)
+
+		s.Require().Equal(1, results[1].Index)
+		s.Require().Nil(results[1].Goods)
+		s.Require().Equal(model.CodeRequired, results[1].Errors["name"])
+
+		s.Require().Equal(2, results[2].Index)
+		s.Require().Empty(results[2].Error)
+		s.Require().Equal("second", results[2].Goods.Name)
+		s.Require().Equal(existing.Priority+2, results[2].Goods.Priority)
+	})
+
+	s.Run("200, nothing valid", func() {
+		var responseData apiserver.BulkResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkCreateEndpoint,
+			model.BulkCreateRequest{
+				ProjectID: project.ID,
+				Goods:     []model.Goods{{Name: ""}},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Len(*responseData.Results, 1)
+		s.Require().Nil((*responseData.Results)[0].Goods)
+	})
+}
+
+func (s *IntegrationTestSuite) TestBulkChange() {
+	project := s.createProject("bulk change project")
+
+	apple := s.createGoodInProject("apple", project.ID)
+	apricot := s.createGoodInProject("apricot", project.ID)
+	banana := s.createGoodInProject("banana", project.ID)
+
+	s.Run("400, ids and filter", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkRemoveEndpoint,
+			model.BulkRemoveRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{apple.ID},
+				Filter:    &model.BulkFilter{NamePrefix: "ap"},
+			},
+			nil,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("400, nothing to update", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkUpdateEndpoint,
+			model.BulkUpdateRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{apple.ID},
+			},
+			nil,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("200, update dry run", func() {
+		var responseData apiserver.BulkChangeResponse
+
+		pattern := "{name} #{id}"
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkUpdateEndpoint,
+			model.BulkUpdateRequest{
+				ProjectID: project.ID,
+				Filter:    &model.BulkFilter{NamePrefix: "AP"},
+				Set:       model.BulkUpdateSet{NamePattern: &pattern},
+				DryRun:    true,
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().True(responseData.DryRun)
+		s.Require().Equal(2, responseData.Affected)
+		s.Require().Equal(fmt.Sprintf("apple #%d", apple.ID), (*responseData.Goods)[0].Name)
+
+		var good model.Goods
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getGoodEndpoint,
+			nil,
+			&good,
+			QueryRequestParams{ID: apple.ID, ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("apple", good.Name)
+	})
+
+	s.Run("200, update by ids", func() {
+		var responseData apiserver.BulkChangeResponse
+
+		description := "fruit"
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkUpdateEndpoint,
+			model.BulkUpdateRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{apple.ID, banana.ID},
+				Set:       model.BulkUpdateSet{Description: &description},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(2, responseData.Affected)
+
+		for _, good := range *responseData.Goods {
+			s.Require().Equal("fruit", good.Description)
+		}
+	})
+
+	s.Run("200, remove dry run", func() {
+		var responseData apiserver.BulkChangeResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkRemoveEndpoint,
+			model.BulkRemoveRequest{
+				ProjectID: project.ID,
+				Filter:    &model.BulkFilter{NamePrefix: "ap"},
+				DryRun:    true,
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(2, responseData.Affected)
+
+		var list apiserver.GetListResponse
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(model.DefaultListLimit, list.Meta.Limit)
+		s.Require().Equal(3, list.Meta.Active)
+	})
+
+	s.Run("200, remove by filter", func() {
+		var responseData apiserver.BulkChangeResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkRemoveEndpoint,
+			model.BulkRemoveRequest{
+				ProjectID: project.ID,
+				Filter:    &model.BulkFilter{NamePrefix: "ap"},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(2, responseData.Affected)
+
+		removed := make(map[int64]bool)
+		for _, good := range *responseData.Goods {
+			s.Require().True(good.Removed)
+			removed[good.ID] = true
+		}
+
+		s.Require().True(removed[apple.ID])
+		s.Require().True(removed[apricot.ID])
+
+		var list apiserver.GetListResponse
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(1, list.Meta.Active)
+		s.Require().Equal(2, list.Meta.Removed)
+	})
+}
+
+func (s *IntegrationTestSuite) TestBatch() {
+	project := s.createProject("batch project")
+	first := s.createGoodInProject("first", project.ID)
+
+	ref := 0
+	description := "made in a batch"
+
+	s.Run("200", func() {
+		var responseData apiserver.BatchResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			batchEndpoint,
+			model.BatchRequest{Operations: []model.BatchOperation{
+				{Op: model.BatchOpCreate, ProjectID: project.ID, Name: "batched"},
+				{Op: model.BatchOpUpdate, Ref: &ref, Name: "batched", Description: &description},
+				{Op: model.BatchOpReprioritize, Ref: &ref, Priority: 1},
+			}},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		results := *responseData.Results
+		s.Require().Len(results, 3)
+
+		created := results[0].Goods
+		s.Require().Equal(description, results[1].Goods.Description)
+
+		changed := make(map[int64]int)
+		for _, good := range *results[2].Priorities {
+			changed[good.ID] = good.Priority
+		}
+
+		s.Require().Equal(1, changed[created.ID])
+
+		var good model.Goods
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getGoodEndpoint,
+			nil,
+			&good,
+			QueryRequestParams{ID: first.ID, ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(2, good.Priority)
+	})
+
+	s.Run("404, rolled back", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			batchEndpoint,
+			model.BatchRequest{Operations: []model.BatchOperation{
+				{Op: model.BatchOpCreate, ProjectID: project.ID, Name: "never committed"},
+				{Op: model.BatchOpRemove, ID: -1, ProjectID: project.ID},
+			}},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+		s.Require().Equal(map[string]any{"index": float64(1)}, responseData.Details)
+
+		var list apiserver.GetListResponse
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(2, list.Meta.Active)
+	})
+
+	s.Run("400, wrong ref", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			batchEndpoint,
+			model.BatchRequest{Operations: []model.BatchOperation{
+				{Op: model.BatchOpRemove, Ref: &ref},
+			}},
+			nil,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func (s *IntegrationTestSuite) TestUpdatePatch() {
+	project := s.createProject("patch project")
+
+	goods := make([]*model.Goods, 0, 3)
+	for i := 0; i < 3; i++ {
+		goods = append(goods, s.createGoodInProject("patched good", project.ID))
+	}
+
+	patch := func(body map[string]any, dest any) *http.Response {
+		return s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			updateEndpoint,
+			body,
+			dest,
+			QueryRequestParams{
+				ID:        goods[2].ID,
+				ProjectID: project.ID,
+			})
+	}
+
+	s.Run("400, null name", func() {
+		resp := patch(map[string]any{"name": nil}, nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("400, wrong priority", func() {
+		resp := patch(map[string]any{"priority": 0}, nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+	})
+
+	s.Run("200, description only", func() {
+		var updated model.Goods
+
+		resp := patch(map[string]any{"description": "only description"}, &updated)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("patched good", updated.Name)
+		s.Require().Equal("only description", updated.Description)
+		s.Require().Equal(3, updated.Priority)
+	})
+
+	s.Run("200, name and priority", func() {
+		var updated model.Goods
+
+		resp := patch(map[string]any{"name": "first now", "priority": 1}, &updated)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("first now", updated.Name)
+		s.Require().Equal("only description", updated.Description)
+		s.Require().Equal(1, updated.Priority)
+		s.Require().Equal(goods[2].Version+2, updated.Version)
+
+		var list apiserver.GetListResponse
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID, Limit: 10})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(goods[2].ID, (*list.Goods)[0].ID)
+		s.Require().Equal(goods[0].ID, (*list.Goods)[1].ID)
+		s.Require().Equal(2, (*list.Goods)[1].Priority)
+	})
+
+	s.Run("200, null description", func() {
+		var updated model.Goods
+
+		resp := patch(map[string]any{"description": nil}, &updated)
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal("first now", updated.Name)
+		s.Require().Empty(updated.Description)
+	})
+
+	s.Run("404, priority in removed project", func() {
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodDelete,
+			deleteProjectEndpoint,
+			nil,
+			nil,
+			QueryProjectParams{ID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		var responseData apiserver.ErrorResponse
+
+		resp = patch(map[string]any{"priority": 1}, &responseData)
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+		s.Require().Equal(4, responseData.Code)
+		s.Require().Equal("errors.project.notFound", responseData.Message)
+	})
+}
+
+func (s *IntegrationTestSuite) TestVersionPreconditions() {
+	project := s.createProject("version project")
+	good := s.createGoodInProject("versioned good", project.ID)
+	s.createGoodInProject("other good", project.ID)
+
+	params := QueryRequestParams{ID: good.ID, ProjectID: project.ID}
+
+	var current model.Goods
+
+	resp := s.sendRequest(context.Background(), http.MethodGet, getGoodEndpoint, nil, &current, params)
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal(fmt.Sprintf("%q", fmt.Sprint(current.Version)), resp.Header.Get("ETag"))
+
+	staleETag := resp.Header.Get("ETag")
+
+	s.Run("200, matching version", func() {
+		var updated model.Goods
+
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPatch,
+			updateEndpoint,
+			map[string]any{"description": "first editor"},
+			&updated,
+			params,
+			map[string]string{"If-Match": staleETag})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(current.Version+1, updated.Version)
+		s.Require().NotEqual(staleETag, resp.Header.Get("ETag"))
+	})
+
+	s.Run("412, update", func() {
+		var latest model.Goods
+
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPatch,
+			updateEndpoint,
+			map[string]any{"description": "second editor"},
+			&latest,
+			params,
+			map[string]string{"If-Match": staleETag})
+
+		s.Require().Equal(http.StatusPreconditionFailed, resp.StatusCode)
+		s.Require().Equal("first editor", latest.Description)
+		s.Require().Equal(current.Version+1, latest.Version)
+	})
+
+	s.Run("412, reprioritize", func() {
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPatch,
+			priorityEndpoint,
+			model.UpdatePriorityRequest{Priority: 2},
+			nil,
+			params,
+			map[string]string{"If-Match": staleETag})
+
+		s.Require().Equal(http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	s.Run("412, remove", func() {
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodDelete,
+			deleteEndpoint,
+			nil,
+			nil,
+			params,
+			map[string]string{"If-Match": staleETag})
+
+		s.Require().Equal(http.StatusPreconditionFailed, resp.StatusCode)
+	})
+
+	s.Run("200, remove with current version", func() {
+		var latest model.Goods
+
+		resp := s.sendRequest(context.Background(), http.MethodGet, getGoodEndpoint, nil, &latest, params)
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+		resp = s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodDelete,
+			deleteEndpoint,
+			nil,
+			nil,
+			params,
+			map[string]string{"If-Match": resp.Header.Get("ETag")})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+	})
+}
+
+func (s *IntegrationTestSuite) TestIdempotency() {
+	project := s.createProject("idempotency project")
+
+	// keys outlive the tables, so every run needs its own
+	key := fmt.Sprintf("create-%d", time.Now().UnixNano())
+	headers := map[string]string{"Idempotency-Key": key}
+	params := QueryRequestParams{ProjectID: project.ID}
+
+	var created model.Goods
+
+	s.Run("201, first request", func() {
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: "retried good"},
+			&created,
+			params,
+			headers)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	})
+
+	s.Run("201, replayed", func() {
+		var replayed model.Goods
+
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: "retried good"},
+			&replayed,
+			params,
+			headers)
+
+		s.Require().Equal(http.StatusCreated, resp.StatusCode)
+		s.Require().Equal(created.ID, replayed.ID)
+		s.Require().Equal(fmt.Sprintf("%q", fmt.Sprint(created.Version)), resp.Header.Get("ETag"))
+
+		var list apiserver.GetListResponse
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(1, list.Meta.Active)
+	})
+
+	s.Run("422, different body", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequestWithHeaders(
+			context.Background(),
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Name: "another good"},
+			&responseData,
+			params,
+			headers)
+
+		s.Require().Equal(http.StatusUnprocessableEntity, resp.StatusCode)
+		s.Require().Equal("errors.idempotency.keyReused", responseData.Message)
+	})
+}
+
+func (s *IntegrationTestSuite) TestIdempotencyCancelledRequest() {
+	ctx := context.Background()
+	project := s.createProject("cancelled idempotency project")
+
+	key := fmt.Sprintf("cancelled-%d", time.Now().UnixNano())
+	headers := map[string]string{"Idempotency-Key": key}
+	params := QueryRequestParams{ProjectID: project.ID}
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	unlocked := make(chan error, 1)
+
+	// the project lock keeps the first request waiting until the client gives up
+	go func() {
+		unlocked <- s.store.WithTx(ctx, func(ctx context.Context) error {
+			_, err := s.store.UpdateProject(ctx, model.UpdateProjectRequest{ID: project.ID, Name: project.Name})
+			if err != nil {
+				return err
+			}
+
+			close(locked)
+			<-release
+
+			return nil
+		})
+	}()
+
+	<-locked
+
+	s.Run("cancelled first request", func() {
+		reqBody, err := json.Marshal(model.Goods{Name: "cancelled good"})
+		s.Require().NoError(err)
+
+		queryParamsValues, err := query.Values(params)
+		s.Require().NoError(err)
+
+		reqCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, bindAddr+createEndpoint+"?"+queryParamsValues.Encode(), bytes.NewReader(reqBody))
+		s.Require().NoError(err)
+
+		req.Header.Set("Idempotency-Key", key)
+
+		_, err = http.DefaultClient.Do(req) //nolint: bodyclose
+		s.Require().ErrorIs(err, context.DeadlineExceeded)
+	})
+
+	close(release)
+	s.Require().NoError(<-unlocked)
+
+	s.Run("201, retried", func() {
+		s.Require().Eventually(func() bool {
+			resp := s.sendRequestWithHeaders(
+				ctx,
+				http.MethodPost,
+				createEndpoint,
+				model.Goods{Name: "cancelled good"},
+				nil,
+				params,
+				headers)
+
+			return resp.StatusCode == http.StatusCreated
+		}, 5*time.Second, 100*time.Millisecond)
+
+		var list apiserver.GetListResponse
+
+		resp := s.sendRequest(
+			ctx,
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&list,
+			QueryListParams{ProjectID: project.ID, Limit: 10})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(1, list.Meta.Active)
+	})
+}
+
+func (s *IntegrationTestSuite) TestValidation() {
+	s.Run("400, create", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			createEndpoint,
+			model.Goods{Description: strings.Repeat("d", model.MaxDescriptionLength+1)},
+			&responseData,
+			QueryRequestParams{ProjectID: -1})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(8, responseData.Code)
+		s.Require().Equal("errors.validation", responseData.Message)
+		s.Require().Equal(map[string]any{
+			"name":        model.CodeRequired,
+			"description": model.CodeTooLong,
+			"projectId":   model.CodeNotFound,
+		}, responseData.Details)
+	})
+
+	s.Run("400, list", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{Limit: model.MaxListLimit + 1, Offset: -1, Sort: "unknown"})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{
+			"limit":  model.CodeOutOfRange,
+			"offset": model.CodeOutOfRange,
+			"sort":   model.CodeUnknownValue,
+		}, responseData.Details)
+	})
+
+	s.Run("400, trash", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			trashEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{Limit: 10, Sort: model.SortName})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{
+			"projectId": model.CodeRequired,
+			"sort":      model.CodeUnsupported,
+		}, responseData.Details)
+	})
+
+	s.Run("404, trash of an unknown project", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			trashEndpoint,
+			nil,
+			&responseData,
+			QueryListParams{Limit: 10, ProjectID: -1})
+
+		s.Require().Equal(http.StatusNotFound, resp.StatusCode)
+		s.Require().Equal(4, responseData.Code)
+	})
+
+	s.Run("400, reprioritize", func() {
+		var responseData apiserver.ErrorResponse
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPatch,
+			priorityEndpoint,
+			model.UpdatePriorityRequest{Priority: 0},
+			&responseData,
+			QueryRequestParams{ID: 1})
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{
+			"projectId":   model.CodeRequired,
+			"newPriority": model.CodeOutOfRange,
+		}, responseData.Details)
+	})
+
+	s.Run("400, bulk update", func() {
+		var responseData apiserver.ErrorResponse
+
+		blank := ""
+		description := strings.Repeat("d", model.MaxDescriptionLength+1)
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkUpdateEndpoint,
+			model.BulkUpdateRequest{
+				ProjectID: s.standardProjectID,
+				IDs:       []int64{1},
+				Set:       model.BulkUpdateSet{NamePattern: &blank, Description: &description},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{
+			"set.namePattern": model.CodeRequired,
+			"set.description": model.CodeTooLong,
+		}, responseData.Details)
+	})
+
+	s.Run("400, bulk update to a long name", func() {
+		project := s.createProject("long names project")
+		good := s.createGoodInProject(strings.Repeat("n", model.MaxNameLength/2+1), project.ID)
+
+		var responseData apiserver.ErrorResponse
+
+		pattern := "{name}{name}"
+
+		resp := s.sendRequest(
+			context.Background(),
+			http.MethodPost,
+			bulkUpdateEndpoint,
+			model.BulkUpdateRequest{
+				ProjectID: project.ID,
+				IDs:       []int64{good.ID},
+				Set:       model.BulkUpdateSet{NamePattern: &pattern},
+			},
+			&responseData,
+			nil)
+
+		s.Require().Equal(http.StatusBadRequest, resp.StatusCode)
+		s.Require().Equal(map[string]any{"set.namePattern": model.CodeTooLong}, responseData.Details)
+
+		var unchanged model.Goods
+
+		resp = s.sendRequest(
+			context.Background(),
+			http.MethodGet,
+			getGoodEndpoint,
+			nil,
+			&unchanged,
+			QueryRequestParams{ID: good.ID, ProjectID: project.ID})
+
+		s.Require().Equal(http.StatusOK, resp.StatusCode)
+		s.Require().Equal(good.Name, unchanged.Name)
+	})
+}
+
+func (s *IntegrationTestSuite) reprioritize(goods *model.Goods, priority int) map[int64]int {
+	var responseData apiserver.ReprioritizeResponse
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodPatch,
+		priorityEndpoint,
+		model.UpdatePriorityRequest{Priority: priority},
+		&responseData,
+		QueryRequestParams{
+			ID:        goods.ID,
+			ProjectID: goods.ProjectID,
+		})
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	changed := make(map[int64]int, len(*responseData.Priorities))
+	for _, good := range *responseData.Priorities {
+		changed[good.ID] = good.Priority
+	}
+
+	return changed
+}
+
+func (s *IntegrationTestSuite) createProject(name string) *model.Project {
+	var project model.Project
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodPost,
+		createProjectEndpoint,
+		model.Project{Name: name},
+		&project,
+		QueryRequestParams{},
+	)
+
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+
+	return &project
+}
+
+func (s *IntegrationTestSuite) createGood(name string) *model.Goods {
+	return s.createGoodInProject(name, s.standardProjectID)
+}
+
+func (s *IntegrationTestSuite) createGoodInProject(name string, projectID int64) *model.Goods {
+	var goods model.Goods
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodPost,
+		createEndpoint,
+		model.Goods{Name: name},
+		&goods,
+		QueryRequestParams{ProjectID: projectID},
+	)
+
+	s.Require().Equal(http.StatusCreated, resp.StatusCode)
+	s.Require().NotEqual(0, goods.ID)
+	s.Require().Equal(name, goods.Name)
+
+	return &goods
+}
+
+// connect opens a pool to the database of the store for the checks the store
+// does not expose.
+func (s *IntegrationTestSuite) connect(ctx context.Context) *pgxpool.Pool {
+	cfg := config.New()
+
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.PGUser, cfg.PGPassword),
+		Host:     fmt.Sprintf("%s:%s", cfg.PGHost, cfg.PGPort),
+		Path:     cfg.PGDatabase,
+		RawQuery: (&url.Values{"sslmode": []string{"disable"}}).Encode(),
+	}
+
+	db, err := pgxpool.New(ctx, dsn.String())
+	s.Require().NoError(err)
+
+	return db
+}
+
+func (s *IntegrationTestSuite) sendRequest(ctx context.Context, method, endpoint string, body interface{}, dest interface{}, params any) *http.Response {
+	s.T().Helper()
+
+	return s.sendRequestWithHeaders(ctx, method, endpoint, body, dest, params, nil)
+}
+
+func (s *IntegrationTestSuite) sendRequestWithHeaders(
+	ctx context.Context,
+	method, endpoint string,
+	body interface{},
+	dest interface{},
+	params any,
+	headers map[string]string,
+) *http.Response {
+	s.T().Helper()
+
+	reqBody, err := json.Marshal(body)
+	s.Require().NoError(err)
+
+	queryParamsValues, err := query.Values(params)
+	s.Require().NoError(err)
+
+	req, err := http.NewRequestWithContext(ctx, method, bindAddr+endpoint+"?"+queryParamsValues.Encode(), bytes.NewReader(reqBody))
+	s.Require().NoError(err)
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	s.Require().NoError(err)
+
+	defer func() {
+		err = resp.Body.Close()
+		s.Require().NoError(err)
+	}()
+
+	if dest != nil {
+		err = json.NewDecoder(resp.Body).Decode(&dest)
+		s.Require().NoError(err)
+	}
+
+	return resp
+}
+
+func (s *IntegrationTestSuite) TestHealth() {
+	var health apiserver.HealthResponse
+
+	resp := s.sendRequest(
+		context.Background(),
+		http.MethodGet,
+		healthEndpoint,
+		nil,
+		&health,
+		nil)
+
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+	s.Require().Equal("CONNECTED", health.Broker)
+}
+
+func (s *IntegrationTestSuite) TestPreviousValues() {
+	ctx := context.Background()
+	project := s.createProject("previous values project")
+
+	goods := make([]*model.Goods, 0, 3)
+	for i := 0; i < 3; i++ {
+		goods = append(goods, s.createGoodInProject(fmt.Sprintf("previous good %d", i), project.ID))
+	}
+
+	s.Run("move", func() {
+		moved, previous, err := s.store.ReprioritizeGoods(ctx, model.UpdatePriorityRequest{
+			ID:        goods[2].ID,
+			ProjectID: project.ID,
+			Priority:  1,
+		})
+		s.Require().NoError(err)
+		s.Require().Len(*moved, 3)
+		s.Require().Len(previous, 3)
+
+		for _, good := range *moved {
+			s.Require().Equal(good.Name, previous[good.ID].Name)
+			s.Require().Equal(previous[good.ID].Version+1, good.Version)
+		}
+
+		s.Require().Equal(3, previous[goods[2].ID].Priority)
+		s.Require().Equal(goods[2].Version, previous[goods[2].ID].Version)
+		s.Require().Equal(1, previous[goods[0].ID].Priority)
+	})
+
+	s.Run("remove and restore", func() {
+		removed, previous, err := s.store.DeleteGoods(ctx, model.Goods{ID: goods[1].ID, ProjectID: project.ID})
+		s.Require().NoError(err)
+		s.Require().True(removed.Removed)
+		s.Require().Equal(goods[1].Name, removed.Name)
+		s.Require().False(previous[goods[1].ID].Removed)
+		s.Require().Equal(removed.Version-1, previous[goods[1].ID].Version)
+		s.Require().Equal(removed.Priority, previous[goods[1].ID].Priority)
+
+		restored, previous, err := s.store.RestoreGoods(ctx, model.Goods{ID: goods[1].ID, ProjectID: project.ID})
+		s.Require().NoError(err)
+		s.Require().False(restored.Removed)
+		s.Require().True(previous[goods[1].ID].Removed)
+		s.Require().NotNil(previous[goods[1].ID].RemovedAt)
+		s.Require().Equal(removed.Version, previous[goods[1].ID].Version)
+		s.Require().Equal(3, restored.Priority)
+	})
+
+	s.Run("bulk update by filter", func() {
+		description := "bulk"
+
+		updated, previous, err := s.store.BulkUpdateGoods(ctx, model.BulkUpdateRequest{
+			ProjectID: project.ID,
+			Filter:    &model.BulkFilter{NamePrefix: "previous good"},
+			Set:       model.BulkUpdateSet{Description: &description},
+		})
+		s.Require().NoError(err)
+		s.Require().Len(*updated, 3)
+
+		for _, good := range *updated {
+			s.Require().Equal("bulk", good.Description)
+			s.Require().Empty(previous[good.ID].Description)
+			s.Require().Equal(good.Priority, previous[good.ID].Priority)
+			s.Require().Equal(good.Version-1, previous[good.ID].Version)
+		}
+	})
+}
+
+func (s *IntegrationTestSuite) TestOutbox() {
+	ctx := context.Background()
+	goods := s.createGood("outbox good")
+
+	resp := s.sendRequest(
+		ctx,
+		http.MethodPatch,
+		updateEndpoint,
+		map[string]any{"description": "relayed"},
+		nil,
+		QueryRequestParams{
+			ID:        goods.ID,
+			ProjectID: goods.ProjectID,
+		})
+	s.Require().Equal(http.StatusOK, resp.StatusCode)
+
+	s.Require().Eventually(func() bool {
+		var pending int
+
+		err := s.store.WithTx(ctx, func(ctx context.Context) error {
+			messages, err := s.store.GetPendingOutbox(ctx, 100)
+			if err != nil {
+				return err
+			}
+
+			pending = len(*messages)
+
+			return nil
+		})
+
+		return err == nil && pending == 0
+	}, 5*time.Second, 100*time.Millisecond)
+
+	pruned, err := s.store.DeleteSentOutbox(ctx, time.Now().Add(time.Minute), 1)
+	s.Require().NoError(err)
+	s.Require().Equal(1, pruned)
 }
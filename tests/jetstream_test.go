@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	chnats "github.com/Saaghh/hezzl-hr/internal/chlogger/nats"
+	"github.com/Saaghh/hezzl-hr/internal/config"
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/Saaghh/hezzl-hr/internal/store/nats"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/stretchr/testify/require"
+)
+
+var errSaveFailed = errors.New("save failed")
+
+// eventStore saves events in memory and fails the first failures saves and
+// saves with a done context.
+type eventStore struct {
+	mu       sync.Mutex
+	failures int
+	events   []model.GoodsEvent
+}
+
+func (s *eventStore) SaveGoodsEvents(ctx context.Context, goods *[]model.GoodsEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if s.failures > 0 {
+		s.failures--
+
+		return errSaveFailed
+	}
+
+	s.events = append(s.events, *goods...)
+
+	return nil
+}
+
+func (s *eventStore) failuresUsed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.failures == 0
+}
+
+func (s *eventStore) saved() []model.GoodsEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]model.GoodsEvent(nil), s.events...)
+}
+
+func runNatsServer(t *testing.T) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+
+	go srv.Start()
+
+	require.True(t, srv.ReadyForConnections(5*time.Second))
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
+
+func TestJetStreamDelivery(t *testing.T) {
+	srv := runNatsServer(t)
+
+	publisher, err := nats.NewPublisher(&config.Config{
+		NatsURLs:            []string{srv.ClientURL()},
+		NatsReconnectWait:   time.Second,
+		NatsMaxReconnects:   -1,
+		NatsJetStream:       true,
+		NatsStream:          "GOODS_LOGS",
+		NatsDuplicateWindow: time.Minute,
+	})
+	require.NoError(t, err)
+
+	defer publisher.Close()
+
+	goods := model.Goods{ID: 1, ProjectID: 1, Name: "good"}
+	created := model.NewGoodsEvent(model.EventTypeCreated, nil, &goods)
+	updated := model.NewGoodsEvent(model.EventTypeUpdated, &goods, &goods)
+
+	// published before chlogger runs, the repeat is a retry of the outbox
+	require.NoError(t, publisher.PublishEvent(created))
+	require.NoError(t, publisher.PublishEvent(created))
+	require.NoError(t, publisher.PublishEvent(updated))
+
+	store := &eventStore{failures: 1}
+
+	sub, err := chnats.NewGoodsEventSubscriber(store, 10, time.Second, 5*time.Second, srv.ClientURL())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		done <- sub.ConsumeEventLogger(ctx, "GOODS_LOGS", "chlogger", "goods_logs")
+	}()
+
+	// the failed save is not acknowledged and the events are delivered again
+	require.Eventually(t, func() bool {
+		return len(store.saved()) == 2
+	}, 30*time.Second, 100*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	saved := store.saved()
+	require.Equal(t, created.EventID, saved[0].EventID)
+	require.Equal(t, updated.EventID, saved[1].EventID)
+	require.Len(t, saved, 2)
+}
+
+func TestSubscriberFlush(t *testing.T) {
+	srv := runNatsServer(t)
+	store := &eventStore{}
+
+	sub, err := chnats.NewGoodsEventSubscriber(store, 100, 100*time.Millisecond, 5*time.Second, srv.ClientURL())
+	require.NoError(t, err)
+
+	_, err = sub.SubscribeEventLogger("goods_logs")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	flusherDone := make(chan struct{})
+
+	go func() {
+		sub.RunFlusher(ctx)
+		close(flusherDone)
+	}()
+
+	publisher, err := nats.NewPublisher(&config.Config{
+		NatsURLs:          []string{srv.ClientURL()},
+		NatsReconnectWait: time.Second,
+		NatsMaxReconnects: -1,
+	})
+	require.NoError(t, err)
+
+	goods := model.Goods{ID: 1, ProjectID: 1, Name: "good"}
+
+	// a batch that does not fill up is saved by the ticker
+	require.NoError(t, publisher.PublishEvent(model.NewGoodsEvent(model.EventTypeCreated, nil, &goods)))
+	require.Eventually(t, func() bool {
+		return len(store.saved()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-flusherDone
+
+	// events received before the shutdown are saved without a tick
+	require.NoError(t, publisher.PublishEvent(model.NewGoodsEvent(model.EventTypeUpdated, &goods, &goods)))
+	publisher.Close()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	require.NoError(t, sub.Shutdown(shutdownCtx))
+	require.Len(t, store.saved(), 2)
+}
+
+func TestSubscriberShutdownAfterDeadline(t *testing.T) {
+	srv := runNatsServer(t)
+	store := &eventStore{failures: 1}
+
+	sub, err := chnats.NewGoodsEventSubscriber(store, 1, time.Hour, 5*time.Second, srv.ClientURL())
+	require.NoError(t, err)
+
+	_, err = sub.SubscribeEventLogger("goods_logs")
+	require.NoError(t, err)
+
+	publisher, err := nats.NewPublisher(&config.Config{
+		NatsURLs:          []string{srv.ClientURL()},
+		NatsReconnectWait: time.Second,
+		NatsMaxReconnects: -1,
+	})
+	require.NoError(t, err)
+
+	goods := model.Goods{ID: 1, ProjectID: 1, Name: "good"}
+
+	// the full batch fails to save and stays queued
+	require.NoError(t, publisher.PublishEvent(model.NewGoodsEvent(model.EventTypeCreated, nil, &goods)))
+	publisher.Close()
+	require.Eventually(t, store.failuresUsed, 5*time.Second, 10*time.Millisecond)
+
+	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
+	shutdownCancel()
+
+	require.NoError(t, sub.Shutdown(shutdownCtx))
+	require.Len(t, store.saved(), 1)
+}
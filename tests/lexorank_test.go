@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/Saaghh/hezzl-hr/internal/config"
+	"github.com/Saaghh/hezzl-hr/internal/lexorank"
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/Saaghh/hezzl-hr/internal/store/pg"
+	"github.com/stretchr/testify/require"
+)
+
+const lexorankMoves = 50
+
+func TestLexorankBetween(t *testing.T) {
+	cases := []struct {
+		prev string
+		next string
+	}{
+		{"", ""},
+		{"", "a"},
+		{"a", ""},
+		{"a", "b"},
+		{"a", "ab"},
+		{"ab", "b"},
+		{"az", "b"},
+		{"0000000010", "0000000011"},
+	}
+
+	for _, c := range cases {
+		rank, err := lexorank.Between(c.prev, c.next)
+		require.NoError(t, err)
+		require.Greater(t, rank, c.prev)
+
+		if c.next != "" {
+			require.Less(t, rank, c.next)
+		}
+	}
+
+	_, err := lexorank.Between("b", "a")
+	require.ErrorIs(t, err, lexorank.ErrInvalidRange)
+
+	_, err = lexorank.Between("a", "a0")
+	require.ErrorIs(t, err, lexorank.ErrInvalidRange)
+}
+
+func TestLexorankRepeatedInsertions(t *testing.T) {
+	prev, next := "", ""
+
+	for i := 0; i < 200; i++ {
+		rank, err := lexorank.Between(prev, next)
+		require.NoError(t, err)
+		require.Greater(t, rank, prev)
+
+		if next != "" {
+			require.Less(t, rank, next)
+		}
+
+		// keep bisecting the same gap to make ranks grow
+		if i%2 == 0 {
+			next = rank
+		} else {
+			prev = rank
+		}
+	}
+}
+
+func TestLexorankSpread(t *testing.T) {
+	ranks := lexorank.Spread(1000)
+
+	require.Len(t, ranks, 1000)
+	require.True(t, sort.StringsAreSorted(ranks))
+
+	for i := 1; i < len(ranks); i++ {
+		require.Less(t, ranks[i-1], ranks[i])
+		require.Len(t, ranks[i], len(ranks[0]))
+
+		_, err := lexorank.Between(ranks[i-1], ranks[i])
+		require.NoError(t, err)
+	}
+}
+
+func (s *IntegrationTestSuite) TestLexorankStore() {
+	ctx := context.Background()
+
+	cfg := config.New()
+	cfg.RankingMode = config.RankingLexorank
+
+	store, err := pg.New(ctx, cfg)
+	s.Require().NoError(err)
+
+	project := s.createProject("lexorank project")
+	order := make([]int64, 0, 5)
+
+	for i := 0; i < 5; i++ {
+		goods, err := store.CreateGoods(ctx, model.Goods{
+			ProjectID: project.ID,
+			Name:      fmt.Sprintf("lexorank good %d", i),
+		})
+		s.Require().NoError(err)
+		s.Require().Equal(i+1, goods.Priority)
+
+		order = append(order, goods.ID)
+	}
+
+	s.Run("list order after moves", func() {
+		move := func(id int64, priority int) {
+			_, _, err := store.ReprioritizeGoods(ctx, model.UpdatePriorityRequest{
+				ID:        id,
+				ProjectID: project.ID,
+				Priority:  priority,
+			})
+			s.Require().NoError(err)
+
+			index := indexOf(order, id)
+			order = append(order[:index], order[index+1:]...)
+			order = append(order[:priority-1], append([]int64{id}, order[priority-1:]...)...)
+
+			s.Require().Equal(order, s.listLexorank(store, project.ID))
+		}
+
+		move(order[4], 1)
+		move(order[0], 5)
+
+		// moving the last good between the first two bisects the same gap
+		for i := 0; i < lexorankMoves; i++ {
+			move(order[4], 2)
+		}
+	})
+
+	s.Run("rebalance keeps order", func() {
+		db := s.connect(ctx)
+		defer db.Close()
+
+		longestRank := func() int {
+			var length int
+
+			err := db.QueryRow(
+				ctx,
+				"SELECT max(length(rank)) FROM goods WHERE project_id = $1 AND removed = false",
+				project.ID,
+			).Scan(&length)
+			s.Require().NoError(err)
+
+			return length
+		}
+
+		before := longestRank()
+
+		rebalanced, err := store.RebalanceRanks(ctx, before-1)
+		s.Require().NoError(err)
+		s.Require().GreaterOrEqual(rebalanced, 1)
+
+		s.Require().Less(longestRank(), before)
+		s.Require().Equal(order, s.listLexorank(store, project.ID))
+	})
+}
+
+// listLexorank returns the ids of the active goods of the project in list
+// order and checks that their priorities are their positions.
+func (s *IntegrationTestSuite) listLexorank(store *pg.Postgres, projectID int64) []int64 {
+	list, err := store.GetGoods(context.Background(), model.ListParams{
+		ProjectID: projectID,
+		Limit:     model.MaxListLimit,
+	})
+	s.Require().NoError(err)
+
+	ids := make([]int64, 0, len(list.GoodsList))
+
+	for i, goods := range list.GoodsList {
+		s.Require().Equal(i+1, goods.Priority)
+
+		ids = append(ids, goods.ID)
+	}
+
+	return ids
+}
+
+func indexOf(ids []int64, id int64) int {
+	for i := range ids {
+		if ids[i] == id {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Saaghh/hezzl-hr/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectValidate(t *testing.T) {
+	long := strings.Repeat("n", model.MaxNameLength+1)
+
+	require.Equal(t, model.ValidationErrors{"name": model.CodeRequired}, model.Project{}.Validate())
+	require.Equal(t, model.ValidationErrors{"name": model.CodeTooLong}, model.UpdateProjectRequest{Name: long}.Validate())
+	require.NoError(t, model.Project{Name: strings.Repeat("n", model.MaxNameLength)}.Validate().Err())
+}
+
+func TestListParamsValidate(t *testing.T) {
+	now := time.Now()
+	earlier := now.Add(-time.Hour)
+
+	violations := model.ListParams{
+		Limit:        -1,
+		PriorityFrom: 5,
+		PriorityTo:   2,
+		CreatedFrom:  &now,
+		CreatedTo:    &earlier,
+		NameMatch:    "fuzzy",
+		Sort:         model.SortName,
+		Cursor:       (&model.Cursor{ProjectID: 1, Priority: 1, ID: 1}).Encode(),
+	}.Validate()
+
+	require.Equal(t, model.ValidationErrors{
+		"limit":      model.CodeOutOfRange,
+		"priorityTo": model.CodeOutOfRange,
+		"createdTo":  model.CodeOutOfRange,
+		"nameMatch":  model.CodeUnknownValue,
+		"cursor":     model.CodeInvalid,
+	}, violations)
+
+	require.NoError(t, model.ListParams{Limit: 10}.Validate().Err())
+}
+
+func TestListParamsWithDefaults(t *testing.T) {
+	require.Equal(t, model.DefaultListLimit, model.ListParams{}.WithDefaults().Limit)
+	require.Equal(t, 10, model.ListParams{Limit: 10}.WithDefaults().Limit)
+	require.Equal(t, -1, model.ListParams{Limit: -1}.WithDefaults().Limit)
+}
+
+func TestListParamsValidateTrash(t *testing.T) {
+	violations := model.ListParams{
+		Limit:  -1,
+		Name:   "good",
+		Sort:   model.SortName,
+		Cursor: (&model.Cursor{ProjectID: 1, Priority: 1, ID: 1}).Encode(),
+	}.ValidateTrash()
+
+	require.Equal(t, model.ValidationErrors{
+		"projectId": model.CodeRequired,
+		"limit":     model.CodeOutOfRange,
+		"name":      model.CodeUnsupported,
+		"sort":      model.CodeUnsupported,
+		"cursor":    model.CodeUnsupported,
+	}, violations)
+
+	require.NoError(t, model.ListParams{Limit: 10, Offset: 10, ProjectID: 1}.ValidateTrash().Err())
+}
+
+func TestProjectListParamsValidate(t *testing.T) {
+	require.Equal(t, model.ValidationErrors{
+		"limit":  model.CodeOutOfRange,
+		"offset": model.CodeOutOfRange,
+	}, model.ProjectListParams{Limit: -1, Offset: -1}.Validate())
+
+	require.NoError(t, model.ProjectListParams{Limit: 10}.Validate().Err())
+}
+
+func TestUpdateGoodsRequestValidate(t *testing.T) {
+	blank := ""
+	priority := 0
+
+	err := model.UpdateGoodsRequest{ID: 1, ProjectID: 1, Name: &blank, Priority: &priority}.Validate().Err()
+	require.True(t, errors.Is(fmt.Errorf("wrapped: %w", err), model.ErrValidation))
+
+	var violations model.ValidationErrors
+
+	require.True(t, errors.As(err, &violations))
+	require.Equal(t, model.CodeRequired, violations["name"])
+	require.Equal(t, model.CodeOutOfRange, violations["priority"])
+
+	require.NoError(t, model.UpdateGoodsRequest{ID: 1, ProjectID: 1}.Validate().Err())
+}
+
+func TestBulkUpdateRequestValidate(t *testing.T) {
+	blank := ""
+	description := strings.Repeat("d", model.MaxDescriptionLength+1)
+
+	require.Equal(t, model.ValidationErrors{
+		"set.namePattern": model.CodeRequired,
+		"set.description": model.CodeTooLong,
+	}, model.BulkUpdateRequest{Set: model.BulkUpdateSet{NamePattern: &blank, Description: &description}}.Validate())
+
+	pattern := "{name}{name}"
+	request := model.BulkUpdateRequest{Set: model.BulkUpdateSet{NamePattern: &pattern}}
+
+	require.NoError(t, request.Validate().Err())
+	require.NoError(t, request.ValidateNames([]model.Goods{{Name: "short"}}).Err())
+	require.Equal(t, model.ValidationErrors{
+		"set.namePattern": model.CodeTooLong,
+	}, request.ValidateNames([]model.Goods{{Name: "short"}, {Name: strings.Repeat("n", model.MaxNameLength+1)}}))
+}
+
+func TestReorderRequestValidate(t *testing.T) {
+	require.Equal(t, model.ValidationErrors{
+		"projectId": model.CodeRequired,
+	}, model.ReorderRequest{IDs: []int64{1}}.Validate())
+
+	require.NoError(t, model.ReorderRequest{ProjectID: 1, IDs: []int64{1}}.Validate().Err())
+}